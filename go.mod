@@ -3,6 +3,7 @@ module github.com/muesli/service-tools
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v0.3.0
 	github.com/coreos/go-systemd v0.0.0-20190321100706-95778dfbb74e
 	github.com/coreos/pkg v0.0.0-20180928190104-399ea9e2e55f // indirect
 	github.com/gdamore/tcell v1.1.1
@@ -14,4 +15,5 @@ require (
 	github.com/rivo/uniseg v0.0.0-20190313204849-f699dde9c340 // indirect
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.3 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
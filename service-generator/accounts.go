@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// readAccounts returns the names and numeric IDs of all accounts in a
+// passwd(5) or group(5) file.
+func readAccounts(filename string) (Strings, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	var res Strings
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		res = append(res, fields[0], fields[2])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	return res, nil
+}
+
+// accountName returns the name of the account created for the service with
+// sysusers.d.
+func accountName() string {
+	if u := createOpts.User; len(u) > 0 && u != "root" {
+		return u
+	}
+
+	return serviceName()
+}
+
+// completeAccounts clears the default root account for dynamic users and
+// picks the account to create with sysusers.d.
+func completeAccounts() {
+	switch {
+	case createOpts.DynamicUser:
+		if createOpts.User == "root" {
+			createOpts.User = ""
+		}
+		if createOpts.Group == "root" {
+			createOpts.Group = ""
+		}
+	case createOpts.SysUsers:
+		createOpts.User = accountName()
+		if len(createOpts.Group) == 0 || createOpts.Group == "root" {
+			createOpts.Group = createOpts.User
+		}
+	}
+}
+
+// validateAccounts checks that the service's user and group exist, unless
+// they get created on the fly.
+func validateAccounts() error {
+	if createOpts.DynamicUser && createOpts.SysUsers {
+		return fmt.Errorf("A dynamic user can't be created with sysusers.d as well")
+	}
+	if createOpts.SysUsers && userMode {
+		return fmt.Errorf("sysusers.d can only create accounts for system services")
+	}
+	if createOpts.DynamicUser || createOpts.SysUsers {
+		return nil
+	}
+
+	if u := createOpts.User; len(u) > 0 && !strings.Contains(u, "%") {
+		users, err := readAccounts(filepath.Join(unitRoot, "/etc/passwd"))
+		if err != nil {
+			return err
+		}
+		if !users.Contains(u) {
+			return fmt.Errorf("No such user: %s (use --sysusers to create it or --dynamicuser)", u)
+		}
+	}
+	if g := createOpts.Group; len(g) > 0 && !strings.Contains(g, "%") {
+		groups, err := readAccounts(filepath.Join(unitRoot, "/etc/group"))
+		if err != nil {
+			return err
+		}
+		if !groups.Contains(g) {
+			return fmt.Errorf("No such group: %s", g)
+		}
+	}
+
+	return nil
+}
+
+// accountFiles returns the sysusers.d and tmpfiles.d snippets for a service.
+// They get installed to the configuration directory the units get installed
+// to, e.g. /etc for /etc/systemd/system, or written next to the generated
+// units.
+func accountFiles(name string) (string, string) {
+	dir := unitDir()
+	if len(dir) == 0 {
+		dir = "."
+	}
+	if filepath.Base(dir) == "system" && filepath.Base(filepath.Dir(dir)) == "systemd" {
+		dir = filepath.Dir(filepath.Dir(dir))
+	}
+
+	return filepath.Join(dir, "sysusers.d", name+".conf"), filepath.Join(dir, "tmpfiles.d", name+".conf")
+}
+
+// writeAccountFiles writes the sysusers.d snippet creating the service's
+// account and the tmpfiles.d snippet creating its home directory.
+func writeAccountFiles(name string) error {
+	if !createOpts.SysUsers {
+		return nil
+	}
+
+	u, g := createOpts.User, createOpts.Group
+	home := filepath.Join("/var/lib", u)
+	sysusers := fmt.Sprintf("u %s - %q %s\n", u, fmt.Sprintf("%s account", createOpts.Description), home)
+	if g != u {
+		sysusers += fmt.Sprintf("g %s -\nm %s %s\n", g, u, g)
+	}
+	tmpfiles := fmt.Sprintf("d %s 0750 %s %s -\n", home, u, g)
+
+	sysusersFile, tmpfilesFile := accountFiles(name)
+	for _, f := range [][2]string{{sysusersFile, sysusers}, {tmpfilesFile, tmpfiles}} {
+		filename, content := f[0], f[1]
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return fmt.Errorf("Could not create directory: %s", err)
+		}
+		changed, err := writeFile(filename, []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+		if changed {
+			fmt.Printf("Generated %s: %s\n%s\n", filepath.Base(filepath.Dir(filename)), filename, content)
+		}
+	}
+
+	return nil
+}
+
+// createAccounts applies the installed sysusers.d and tmpfiles.d snippets, so
+// the account exists before the service gets started.
+func createAccounts(name string) error {
+	if !createOpts.SysUsers {
+		return nil
+	}
+
+	sysusersFile, tmpfilesFile := accountFiles(name)
+	if out, err := applySnippet("systemd-sysusers", sysusersFile); err != nil {
+		return fmt.Errorf("Could not create account: %s %s", err, out)
+	}
+	if out, err := applySnippet("systemd-tmpfiles", tmpfilesFile, "--create"); err != nil {
+		return fmt.Errorf("Could not create home directory: %s %s", err, out)
+	}
+	fmt.Printf("Created account %s\n", createOpts.User)
+
+	return nil
+}
+
+// applySnippet runs a systemd tool on a snippet. Below a root the tools
+// would look up the snippet there as well, so it gets passed on stdin.
+func applySnippet(tool, filename string, args ...string) ([]byte, error) {
+	if len(unitRoot) == 0 {
+		return exec.Command(tool, append(args, filename)...).CombinedOutput()
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(tool, append(args, "--root="+unitRoot, "-")...)
+	cmd.Stdin = f
+	return cmd.CombinedOutput()
+}
+
+func init() {
+	createCmd.PersistentFlags().BoolVar(&createOpts.DynamicUser, "dynamicuser", false, "Run the service as a transient user allocated when it starts")
+	createCmd.PersistentFlags().BoolVar(&createOpts.SysUsers, "sysusers", false, "Create a dedicated account for the service with sysusers.d")
+}
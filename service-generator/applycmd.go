@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	applyFile string
+
+	applyCmd = &cobra.Command{
+		Use:   "apply -f <spec>",
+		Short: "creates Unit files for all services of a spec",
+		Long: `The apply command creates Unit files for all services described in a YAML, TOML or JSON spec.
+Its "services" map the service names to their options, which are named in
+lowercase (e.g. "exec", "workingdirectory" or "timer.oncalendar").
+Options in "defaults" apply to all services. Files with unchanged content
+don't get rewritten, so applying a spec again is safe`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(applyFile) == 0 {
+				return fmt.Errorf("Need a spec file, given with -f")
+			}
+			services, err := readSpec(applyFile)
+			if err != nil {
+				return err
+			}
+
+			// validate all services before touching any files
+			var validated []CreateOptions
+			for _, opts := range services {
+				createOpts = opts
+				if err := validateTypes(); err != nil {
+					return fmt.Errorf("%s: %s", opts.Name, err)
+				}
+				completeOptions()
+				for k, v := range opts.Hardening {
+					createOpts.Hardening[k] = v
+				}
+				normalizeOptions()
+				if err := validate(); err != nil {
+					return fmt.Errorf("%s: %s", opts.Name, err)
+				}
+				validated = append(validated, createOpts)
+			}
+
+			for _, opts := range validated {
+				createOpts = opts
+				extraOptions = nil
+				if err := executeCreate(); err != nil {
+					return fmt.Errorf("%s: %s", opts.Name, err)
+				}
+			}
+
+			if len(changedFiles) == 0 {
+				fmt.Println("No files changed")
+				return nil
+			}
+			fmt.Println("Changed files:")
+			for _, f := range changedFiles {
+				fmt.Printf("  %s\n", f)
+			}
+			return nil
+		},
+	}
+)
+
+// readSpec returns the options of all services described in a spec file,
+// sorted by name. The format is detected from the file's extension.
+func readSpec(filename string) ([]CreateOptions, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	var spec map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		var m map[interface{}]interface{}
+		err = yaml.Unmarshal(b, &m)
+		if err == nil {
+			spec, err = specMap(m)
+		}
+	case ".toml":
+		err = toml.Unmarshal(b, &spec)
+	case ".json":
+		err = json.Unmarshal(b, &spec)
+	default:
+		return nil, fmt.Errorf("Unknown spec format: %s (expected .yaml, .toml or .json)", filepath.Ext(filename))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse spec: %s", err)
+	}
+
+	for k := range spec {
+		if k != "defaults" && k != "services" {
+			return nil, fmt.Errorf("Unknown spec section: %s (expected defaults or services)", k)
+		}
+	}
+	defaults, ok := spec["defaults"].(map[string]interface{})
+	if !ok && spec["defaults"] != nil {
+		return nil, fmt.Errorf("Invalid defaults in spec")
+	}
+	services, ok := spec["services"].(map[string]interface{})
+	if !ok || len(services) == 0 {
+		return nil, fmt.Errorf("Could not find any services in %s", filename)
+	}
+
+	var names []string
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// services start out with the create command's defaults
+	base := createOpts
+	var res []CreateOptions
+	for _, name := range names {
+		service, ok := services[name].(map[string]interface{})
+		if !ok && services[name] != nil {
+			return nil, fmt.Errorf("Invalid spec for %s", name)
+		}
+
+		b, err := json.Marshal(mergeSpec(defaults, service))
+		if err != nil {
+			return nil, err
+		}
+		opts := base
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&opts); err != nil {
+			return nil, fmt.Errorf("Invalid spec for %s: %s", name, err)
+		}
+		opts.Name = name
+
+		res = append(res, opts)
+	}
+
+	return res, nil
+}
+
+// specMap converts the maps decoded from YAML into maps with string keys,
+// which can be encoded to JSON.
+func specMap(m map[interface{}]interface{}) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	for k, v := range m {
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid key: %v", k)
+		}
+
+		switch e := v.(type) {
+		case map[interface{}]interface{}:
+			sm, err := specMap(e)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = sm
+		case []interface{}:
+			for i, le := range e {
+				if lm, ok := le.(map[interface{}]interface{}); ok {
+					sm, err := specMap(lm)
+					if err != nil {
+						return nil, err
+					}
+					e[i] = sm
+				}
+			}
+			res[key] = e
+		default:
+			res[key] = v
+		}
+	}
+
+	return res, nil
+}
+
+// mergeSpec returns the options of a service merged over the defaults.
+// Nested maps, like the timer options, get merged as well.
+func mergeSpec(defaults, service map[string]interface{}) map[string]interface{} {
+	res := map[string]interface{}{}
+	for k, v := range defaults {
+		res[k] = v
+	}
+	for k, v := range service {
+		dm, dok := res[k].(map[string]interface{})
+		sm, sok := v.(map[string]interface{})
+		if dok && sok {
+			res[k] = mergeSpec(dm, sm)
+			continue
+		}
+		res[k] = v
+	}
+
+	return res
+}
+
+func init() {
+	applyCmd.PersistentFlags().StringVarP(&applyFile, "file", "f", "", "Spec file describing the services (.yaml, .toml or .json)")
+
+	RootCmd.AddCommand(applyCmd)
+}
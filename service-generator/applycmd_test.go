@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadSpec(t *testing.T) {
+	for _, filename := range []string{"testdata/apply/stack.yaml", "testdata/apply/stack.toml", "testdata/apply/stack.json"} {
+		createOpts = CreateOptions{}
+		services, err := readSpec(filename)
+		if err != nil {
+			t.Errorf("readSpec(%s): %s", filename, err)
+			continue
+		}
+		if len(services) != 2 {
+			t.Errorf("readSpec(%s): expected 2 services, got %d", filename, len(services))
+			continue
+		}
+
+		cleanup, web := services[0], services[1]
+		if cleanup.Name != "cleanup" || web.Name != "web" {
+			t.Errorf("readSpec(%s): expected the services sorted by name, got %s and %s", filename, cleanup.Name, web.Name)
+		}
+		if web.Exec != "/usr/bin/web --port 8080" || web.Description != "Web frontend" {
+			t.Errorf("readSpec(%s): unexpected web service: %+v", filename, web)
+		}
+		if !reflect.DeepEqual(web.Environment, []string{"LANG=C.UTF-8"}) {
+			t.Errorf("readSpec(%s): unexpected environment: %q", filename, web.Environment)
+		}
+
+		// defaults apply unless overridden
+		if web.User != "app" || cleanup.User != "root" {
+			t.Errorf("readSpec(%s): expected users app and root, got %s and %s", filename, web.User, cleanup.User)
+		}
+		if web.After != "network-online.target" || cleanup.RestartSec != "5s" {
+			t.Errorf("readSpec(%s): defaults weren't applied: %+v", filename, cleanup)
+		}
+		if cleanup.Timer.OnCalendar != "daily" || !cleanup.Timer.Persistent {
+			t.Errorf("readSpec(%s): unexpected timer: %+v", filename, cleanup.Timer)
+		}
+	}
+}
+
+func TestReadInvalidSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		filename string
+		spec     string
+	}{
+		{"empty.yaml", "defaults:\n  user: app\n"},
+		{"section.yaml", "service:\n  web:\n    exec: /usr/bin/web\n"},
+		{"unknown.yaml", "services:\n  web:\n    executable: /usr/bin/web\n"},
+		// internal options are no spec keys
+		{"name.yaml", "services:\n  web:\n    name: other\n"},
+		{"enabled.yaml", "services:\n  web:\n    timer:\n      enabled: true\n"},
+		{"install.yaml", "services:\n  web:\n    install:\n      install: true\n"},
+		{"envimport.yaml", "services:\n  web:\n    envimport: .env\n"},
+		{"type.json", `{"services": {"web": {"exec": 1}}}`},
+		{"format.ini", "[services]\n"},
+		{"broken.toml", "[services\n"},
+	}
+
+	for _, test := range tests {
+		filename := filepath.Join(dir, test.filename)
+		if err := ioutil.WriteFile(filename, []byte(test.spec), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		createOpts = CreateOptions{}
+		if _, err := readSpec(filename); err == nil {
+			t.Errorf("readSpec(%s): expected an error", test.filename)
+		}
+	}
+}
+
+func TestApplyIdempotence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	createOpts = CreateOptions{}
+	services, err := readSpec("testdata/apply/stack.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apply := func() Strings {
+		changedFiles = Strings{}
+		for _, opts := range services {
+			createOpts = opts
+			extraOptions = nil
+			completeOptions()
+			normalizeOptions()
+
+			base := filepath.Join(dir, opts.Name)
+			if err := writeUnit(base+".service", serviceOptions()); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeCompanionUnits(base); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return changedFiles
+	}
+
+	if changed := apply(); len(changed) != 3 {
+		t.Errorf("Expected the first apply to write 3 files, got %v", changed)
+	}
+	if changed := apply(); len(changed) != 0 {
+		t.Errorf("Expected applying again to change nothing, got %v", changed)
+	}
+}
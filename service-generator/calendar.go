@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	calendarShorthands = Strings{"minutely", "hourly", "daily", "weekly", "monthly", "yearly", "annually", "quarterly", "semiannually"}
+	weekdays           = Strings{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	weekdaysLong       = Strings{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+)
+
+// validateCalendar checks a systemd calendar event expression like
+// "Mon..Fri *-*-* 10:00:00" or "weekly", see systemd.time(7).
+func validateCalendar(s string) error {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return fmt.Errorf("Empty calendar expression")
+	}
+	if calendarShorthands.Contains(strings.ToLower(s)) {
+		return nil
+	}
+
+	fields := strings.Fields(s)
+	if isWeekdaySpec(fields[0]) {
+		if err := validateWeekdays(fields[0]); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && strings.ContainsAny(fields[0], "-~") && !strings.Contains(fields[0], ":") {
+		if err := validateDate(fields[0]); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && strings.Contains(fields[0], ":") {
+		if err := validateTime(fields[0]); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		if err := validateTimezone(fields[0]); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("Unexpected calendar component: %s", fields[0])
+	}
+
+	return nil
+}
+
+func isWeekdaySpec(s string) bool {
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return !strings.Contains(s, "/") && s != "UTC"
+		}
+	}
+
+	return false
+}
+
+func weekdayIndex(s string) int {
+	s = strings.ToLower(s)
+	if i := weekdays.IndexOf(s); i >= 0 {
+		return i
+	}
+
+	return weekdaysLong.IndexOf(s)
+}
+
+func validateWeekdays(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		r := strings.SplitN(part, "..", 2)
+		if len(r) == 1 {
+			r = strings.SplitN(part, "-", 2)
+		}
+		for _, d := range r {
+			if weekdayIndex(d) < 0 {
+				return fmt.Errorf("Invalid weekday in calendar expression: %s", d)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateDate(s string) error {
+	// the last component may be separated with a '~' to count from the end
+	// of the month
+	s = strings.Replace(s, "~", "-", 1)
+	parts := strings.Split(s, "-")
+
+	switch len(parts) {
+	case 2:
+		parts = append([]string{"*"}, parts...)
+	case 3:
+	default:
+		return fmt.Errorf("Invalid date in calendar expression: %s", s)
+	}
+
+	if err := validateCalendarComponent(parts[0], 1970, 2199); err != nil {
+		return fmt.Errorf("Invalid year in calendar expression: %s", err)
+	}
+	if err := validateCalendarComponent(parts[1], 1, 12); err != nil {
+		return fmt.Errorf("Invalid month in calendar expression: %s", err)
+	}
+	if err := validateCalendarComponent(parts[2], 1, 31); err != nil {
+		return fmt.Errorf("Invalid day in calendar expression: %s", err)
+	}
+
+	return nil
+}
+
+func validateTime(s string) error {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("Invalid time in calendar expression: %s", s)
+	}
+
+	if err := validateCalendarComponent(parts[0], 0, 23); err != nil {
+		return fmt.Errorf("Invalid hour in calendar expression: %s", err)
+	}
+	if err := validateCalendarComponent(parts[1], 0, 59); err != nil {
+		return fmt.Errorf("Invalid minute in calendar expression: %s", err)
+	}
+	if len(parts) == 3 {
+		// seconds may carry a fractional part
+		sec := strings.SplitN(parts[2], ".", 2)[0]
+		if err := validateCalendarComponent(sec, 0, 59); err != nil {
+			return fmt.Errorf("Invalid second in calendar expression: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func validateTimezone(s string) error {
+	if s == "UTC" {
+		return nil
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return fmt.Errorf("Unknown timezone in calendar expression: %s", s)
+	}
+
+	return nil
+}
+
+// validateCalendarComponent checks a comma separated list of values, ranges
+// ("a..b") and repetitions ("a/b" or "a..b/c") to be within min and max.
+func validateCalendarComponent(s string, min, max int) error {
+	for _, part := range strings.Split(s, ",") {
+		if part == "*" {
+			continue
+		}
+
+		rep := strings.SplitN(part, "/", 2)
+		if len(rep) == 2 {
+			if n, err := strconv.Atoi(rep[1]); err != nil || n <= 0 {
+				return fmt.Errorf("%s", part)
+			}
+			if rep[0] == "*" {
+				continue
+			}
+		}
+
+		for _, v := range strings.SplitN(rep[0], "..", 2) {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < min || n > max {
+				return fmt.Errorf("%s", part)
+			}
+		}
+	}
+
+	return nil
+}
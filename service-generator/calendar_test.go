@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestValidateCalendar(t *testing.T) {
+	tests := []struct {
+		s     string
+		valid bool
+	}{
+		{"daily", true},
+		{"Weekly", true},
+		{"quarterly", true},
+		{"*-*-* 03:00:00", true},
+		{"Mon..Fri *-*-* 10:00:00", true},
+		{"Sat,Sun 12:00", true},
+		{"mon-wed 08:30", true},
+		{"*-*~01", true},
+		{"2024-02-29 08:00 UTC", true},
+		{"*:0/15", true},
+		{"*-*-* *:00/15:00", true},
+		{"*-01,07-01 00:00:00", true},
+		{"*-*-1..7 12:00:30.5", true},
+		{"", false},
+		{"Funday 10:00", false},
+		{"daily 10:00", false},
+		{"*-13-01 00:00", false},
+		{"*-*-32 00:00", false},
+		{"25:00", false},
+		{"12:60", false},
+		{"12:00:61", false},
+		{"*:0/0", false},
+		{"1-2-3-4", false},
+		{"12:00 Mars/Olympus_Mons", false},
+		{"12:00 UTC extra", false},
+	}
+
+	for _, test := range tests {
+		err := validateCalendar(test.s)
+		if test.valid && err != nil {
+			t.Errorf("validateCalendar(%q): %s", test.s, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validateCalendar(%q): expected an error", test.s)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// execPrefixes are the special characters a command line may start with,
+// see systemd.service(5).
+const execPrefixes = "-@+!:"
+
+// Command is a command line as used by ExecStart and friends.
+type Command struct {
+	Prefix     string
+	Executable string
+	// Args are kept as written, including their quoting
+	Args string
+}
+
+// parseCommand splits a command line into its prefixes, the executable and
+// its arguments.
+func parseCommand(s string) (Command, error) {
+	s = strings.TrimSpace(s)
+	rest := strings.TrimLeft(s, execPrefixes)
+	c := Command{Prefix: s[:len(s)-len(rest)]}
+	if err := validatePrefix(c.Prefix); err != nil {
+		return c, err
+	}
+
+	// the arguments start after the first unquoted whitespace
+	var quote rune
+	escaped := false
+	end := len(rest)
+	for i, r := range rest {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch {
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n':
+			end = i
+		}
+		if end < len(rest) {
+			break
+		}
+	}
+
+	words, err := splitQuoted(rest)
+	if err != nil {
+		return c, err
+	}
+	if len(words) == 0 || end == 0 {
+		return c, fmt.Errorf("Missing executable in: %s", s)
+	}
+	c.Executable = words[0]
+	c.Args = strings.TrimSpace(rest[end:])
+
+	return c, nil
+}
+
+// validatePrefix checks that prefixes are neither repeated nor combined with
+// conflicting ones.
+func validatePrefix(prefix string) error {
+	privileged := 0
+	for _, p := range []string{"!!", "-", "@", "+", "!", ":"} {
+		n := strings.Count(prefix, p)
+		if p == "!" {
+			// not part of a "!!"
+			n -= 2 * strings.Count(prefix, "!!")
+		}
+		if n > 1 {
+			return fmt.Errorf("Repeated prefix %s in: %s", p, prefix)
+		}
+		if p == "+" || p == "!" || p == "!!" {
+			privileged += n
+		}
+	}
+	if privileged > 1 {
+		return fmt.Errorf("Prefixes +, ! and !! can't be combined: %s", prefix)
+	}
+
+	return nil
+}
+
+// String returns the command line, quoting the executable if necessary.
+func (c Command) String() string {
+	s := c.Prefix + quoteWord(c.Executable)
+	if len(c.Args) > 0 {
+		s += " " + c.Args
+	}
+
+	return s
+}
+
+// resolveCommand returns a command line with its executable resolved to an
+// absolute path. Commands which can't be resolved are returned unchanged.
+func resolveCommand(s string) string {
+	s = strings.TrimSpace(s)
+	c, err := parseCommand(s)
+	if len(s) == 0 || err != nil {
+		return s
+	}
+	path, err := resolveExecutable(c.Executable)
+	if err != nil {
+		return s
+	}
+	c.Executable = path
+
+	return c.String()
+}
+
+// resolveExecutable returns the absolute path of an executable, looking up
+// bare program names in PATH like a shell would.
+func resolveExecutable(path string) (string, error) {
+	switch {
+	case strings.Contains(path, "%"):
+		// the path depends on specifiers and can't be resolved here
+		return path, nil
+	case !strings.Contains(path, "/"):
+		p, err := exec.LookPath(path)
+		if err != nil {
+			return path, fmt.Errorf("Could not find executable: %s is not in PATH", path)
+		}
+		return filepath.Abs(p)
+	default:
+		return filepath.Abs(path)
+	}
+}
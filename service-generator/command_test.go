@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Command
+		err  bool
+	}{
+		{s: "/usr/bin/app", want: Command{Executable: "/usr/bin/app"}},
+		{s: "  /bin/true  ", want: Command{Executable: "/bin/true"}},
+		{s: "-/usr/bin/app --port 8080", want: Command{Prefix: "-", Executable: "/usr/bin/app", Args: "--port 8080"}},
+		{s: "@+/bin/sh sh -c 'echo hi'", want: Command{Prefix: "@+", Executable: "/bin/sh", Args: "sh -c 'echo hi'"}},
+		{s: "!!/usr/bin/app", want: Command{Prefix: "!!", Executable: "/usr/bin/app"}},
+		{s: `"/opt/my app/run" --flag`, want: Command{Executable: "/opt/my app/run", Args: "--flag"}},
+		{s: `/opt/my\ app/run`, want: Command{Executable: "/opt/my app/run"}},
+		{s: "node server.js", want: Command{Executable: "node", Args: "server.js"}},
+		{s: "", err: true},
+		{s: "-", err: true},
+		{s: "--/bin/true", err: true},
+		{s: "+!/bin/true", err: true},
+		{s: "/bin/sh -c 'unterminated", err: true},
+	}
+
+	for _, test := range tests {
+		c, err := parseCommand(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseCommand(%q): expected an error, got %+v", test.s, c)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCommand(%q): %s", test.s, err)
+			continue
+		}
+		if c != test.want {
+			t.Errorf("parseCommand(%q) = %+v, expected %+v", test.s, c, test.want)
+		}
+	}
+}
+
+func TestValidatePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{"", true},
+		{"-", true},
+		{"-@:", true},
+		{"-@+", true},
+		{"!!", true},
+		{"!!-", true},
+		{"--", false},
+		{"::", false},
+		{"+!", false},
+		{"+!!", false},
+		{"!!!", false},
+	}
+
+	for _, test := range tests {
+		err := validatePrefix(test.prefix)
+		if test.valid && err != nil {
+			t.Errorf("validatePrefix(%q): %s", test.prefix, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validatePrefix(%q): expected an error", test.prefix)
+		}
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		c    Command
+		want string
+	}{
+		{Command{Executable: "/usr/bin/app"}, "/usr/bin/app"},
+		{Command{Prefix: "-", Executable: "/usr/bin/app", Args: "--port 8080"}, "-/usr/bin/app --port 8080"},
+		{Command{Executable: "/opt/my app/run", Args: "'a b'"}, `"/opt/my app/run" 'a b'`},
+	}
+
+	for _, test := range tests {
+		if s := test.c.String(); s != test.want {
+			t.Errorf("%+v.String() = %q, expected %q", test.c, s, test.want)
+		}
+	}
+}
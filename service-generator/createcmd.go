@@ -1,47 +1,108 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/coreos/go-systemd/unit"
-	"github.com/rivo/tview"
 	"github.com/spf13/cobra"
 )
 
+// CreateOptions describe a service. Their json tags name the options of apply
+// specs, which get decoded as JSON whatever format they're written in.
 type CreateOptions struct {
-	Type        string
-	Description string
-
-	Exec          string
-	ExecStartPre  string
-	ExecStartPost string
-	ExecReload    string
-	ExecStop      string
-	ExecStopPost  string
-
-	WorkingDirectory string
-	RootDirectory    string
-	User             string
-	Group            string
-
-	Restart         string
-	RestartSec      string
-	TimeoutStartSec string
-	TimeoutStopSec  string
-
-	After    string
-	WantedBy string
+	Name        string `json:"-"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	PIDFile     string `json:"pidfile"`
+
+	Exec          string   `json:"exec"`
+	ExecStartPre  []string `json:"execstartpre"`
+	ExecStartPost []string `json:"execstartpost"`
+	ExecReload    string   `json:"execreload"`
+	ExecStop      []string `json:"execstop"`
+	ExecStopPost  []string `json:"execstoppost"`
+
+	WorkingDirectory string `json:"workingdirectory"`
+	RootDirectory    string `json:"rootdirectory"`
+	User             string `json:"user"`
+	Group            string `json:"group"`
+	DynamicUser      bool   `json:"dynamicuser"`
+	SysUsers         bool   `json:"sysusers"`
+
+	RuntimeDirectory           string `json:"runtimedirectory"`
+	RuntimeDirectoryMode       string `json:"runtimedirectorymode"`
+	StateDirectory             string `json:"statedirectory"`
+	StateDirectoryMode         string `json:"statedirectorymode"`
+	CacheDirectory             string `json:"cachedirectory"`
+	CacheDirectoryMode         string `json:"cachedirectorymode"`
+	LogsDirectory              string `json:"logsdirectory"`
+	LogsDirectoryMode          string `json:"logsdirectorymode"`
+	ConfigurationDirectory     string `json:"configurationdirectory"`
+	ConfigurationDirectoryMode string `json:"configurationdirectorymode"`
+
+	Restart         string `json:"restart"`
+	RestartSec      string `json:"restartsec"`
+	TimeoutStartSec string `json:"timeoutstartsec"`
+	TimeoutStopSec  string `json:"timeoutstopsec"`
+
+	After      string `json:"after"`
+	WantedBy   string `json:"wantedby"`
+	Requires   string `json:"requires"`
+	Wants      string `json:"wants"`
+	BindsTo    string `json:"bindsto"`
+	PartOf     string `json:"partof"`
+	Conflicts  string `json:"conflicts"`
+	Before     string `json:"before"`
+	RequiredBy string `json:"requiredby"`
+	Also       string `json:"also"`
+
+	MemoryMax   string `json:"memorymax"`
+	MemoryHigh  string `json:"memoryhigh"`
+	CPUQuota    string `json:"cpuquota"`
+	CPUWeight   string `json:"cpuweight"`
+	IOWeight    string `json:"ioweight"`
+	TasksMax    string `json:"tasksmax"`
+	LimitNOFILE string `json:"limitnofile"`
+	Slice       string `json:"slice"`
+
+	Environment      []string `json:"environment"`
+	EnvironmentFiles []string `json:"environmentfiles"`
+	EnvImport        string   `json:"-"`
+
+	LoadCredentials []string `json:"loadcredentials"`
+	SetCredentials  []string `json:"setcredentials"`
+
+	HardeningProfile string            `json:"hardeningprofile"`
+	Hardening        map[string]string `json:"hardening"`
+
+	Template  bool `json:"template"`
+	Instances int  `json:"instances"`
+
+	Logging LoggingOptions `json:"logging"`
+	Timer   TimerOptions   `json:"timer"`
+	Socket  SocketOptions  `json:"socket"`
+	Install InstallOptions `json:"install"`
 }
 
 var (
 	createOpts = CreateOptions{}
-	types      = Strings{"simple", "forking", "oneshot", "dbus", "notify", "idle"}
+	types      = Strings{"simple", "exec", "forking", "oneshot", "dbus", "notify", "notify-reload", "idle"}
 	restarts   = Strings{"no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog"}
 
+	// commandHooks are the options which may hold several commands, run in
+	// order
+	commandHooks = Strings{"ExecStartPre", "ExecStartPost", "ExecStop", "ExecStopPost"}
+
+	// changedFiles are the files which got written because their content
+	// changed
+	changedFiles = Strings{}
+
 	createCmd = &cobra.Command{
 		Use:   "create <executable> <description> [after] [wanted-by]",
 		Short: "creates a new Unit file",
@@ -52,13 +113,8 @@ var (
 				return fmt.Errorf("Can't find systemd targets: %s", err)
 			}
 
-			createOpts.Type = strings.ToLower(createOpts.Type)
-			if !types.Contains(createOpts.Type) {
-				return fmt.Errorf("No such service type: %s", createOpts.Type)
-			}
-			createOpts.Restart = strings.ToLower(createOpts.Restart)
-			if !restarts.Contains(createOpts.Restart) {
-				return fmt.Errorf("No such restart type: %s", createOpts.Restart)
+			if err := validateTypes(); err != nil {
+				return err
 			}
 
 			switch len(args) {
@@ -75,158 +131,308 @@ var (
 				createOpts.Exec = args[0]
 			}
 
+			completeOptions()
+
 			if len(args) >= 2 {
+				normalizeOptions()
 				if err := validate(); err != nil {
 					return err
 				}
 				return executeCreate()
 			}
-			app := tview.NewApplication()
-			form := tview.NewForm()
-
-			descriptionField := tview.NewInputField().
-				SetLabel("Description:").
-				SetText(createOpts.Description).
-				SetFieldWidth(40).
-				SetAcceptanceFunc(nil).
-				SetChangedFunc(func(s string) {
-					createOpts.Description = s
-				})
-
-			form.
-				AddDropDown("Type:", types, types.IndexOf(createOpts.Type), func(s string, i int) {
-					createOpts.Type = s
-				}).
-				AddInputField("Exec on start:", createOpts.Exec, 40, nil, func(s string) {
-					createOpts.Exec = s
-					descriptionField.SetText(fmt.Sprintf("%s service", filepath.Base(createOpts.Exec)))
-				}).
-				AddFormItem(descriptionField).
-				AddInputField("Exec on stop:", createOpts.ExecStop, 40, nil, func(s string) {
-					createOpts.ExecStop = s
-				}).
-				AddInputField("Exec on reload:", createOpts.ExecReload, 40, nil, func(s string) {
-					createOpts.ExecReload = s
-				}).
-				AddDropDown("Restarts on:", restarts, restarts.IndexOf(createOpts.Restart), func(s string, i int) {
-					createOpts.Restart = s
-				}).
-				AddDropDown("Start after target:", ts.Strings(), Strings(ts.Strings()).IndexOf(createOpts.After), func(s string, i int) {
-					createOpts.After = s
-				}).
-				AddDropDown("Wanted by target:", ts.Strings(), Strings(ts.Strings()).IndexOf(createOpts.WantedBy), func(s string, i int) {
-					createOpts.WantedBy = s
-				})
-
-			var apperr error
-			form.
-				AddButton("Create", func() {
-					app.Stop()
-					if err := validate(); err != nil {
-						apperr = err
-					} else {
-						executeCreate()
-					}
-				}).
-				AddButton("Cancel", func() {
-					app.Stop()
-				})
-
-			form.SetBorder(true).SetTitle("Create new service").SetTitleAlign(tview.AlignCenter)
-			if err := app.SetRoot(form, true).Run(); err != nil {
-				return err
-			}
-			return apperr
+			return runForm("Create new service", "Create", ts, servicePreview, validated(executeCreate))
 		},
 	}
 )
 
-func validateExecutables(executable string, allowEmpty bool) (string, error) {
+// completeOptions applies the hardening profile and enables the companion
+// units implied by the options set, before they get edited in the form.
+func completeOptions() {
+	applyHardeningProfile(createOpts.HardeningProfile)
+
+	t := createOpts.Timer
+	if len(t.OnCalendar) > 0 || len(t.OnBootSec) > 0 || len(t.OnUnitActiveSec) > 0 {
+		createOpts.Timer.Enabled = true
+	}
+	so := createOpts.Socket
+	if len(so.ListenStream) > 0 || len(so.ListenDatagram) > 0 || len(so.ListenFIFO) > 0 {
+		createOpts.Socket.Enabled = true
+	}
+
+	if userMode && len(createOpts.WantedBy) == 0 {
+		createOpts.WantedBy = "default.target"
+	}
+}
+
+// normalizeOptions brings the options into the form they get written in. It
+// gets applied before validating and writing them, as well as for previews,
+// so it must be safe to apply repeatedly.
+func normalizeOptions() {
+	createOpts.Type = strings.ToLower(createOpts.Type)
+	createOpts.Restart = strings.ToLower(createOpts.Restart)
+
+	createOpts.Exec = resolveCommand(createOpts.Exec)
+	createOpts.ExecReload = resolveCommand(createOpts.ExecReload)
+	for _, hook := range commandHooks {
+		// replace the list, previews normalize a shallow copy of the options
+		cmds := hookCommands(hook)
+		var resolved []string
+		for _, c := range *cmds {
+			resolved = append(resolved, resolveCommand(c))
+		}
+		*cmds = resolved
+	}
+
+	// User units always run as the user owning the service manager
+	if userMode {
+		createOpts.User = ""
+		createOpts.Group = ""
+	}
+	completeAccounts()
+
+	// activated services don't get started on their own
+	if createOpts.Timer.Enabled {
+		createOpts.Type = "oneshot"
+		createOpts.WantedBy = ""
+	}
+	if createOpts.Socket.Enabled {
+		createOpts.WantedBy = ""
+	}
+
+	if createOpts.Instances > 0 {
+		createOpts.Install.Enable = true
+	}
+	if createOpts.Install.Enable || createOpts.Install.Start || len(createOpts.Install.UnitDir) > 0 {
+		createOpts.Install.Install = true
+	}
+}
+
+func validateExecutables(executable string, allowEmpty bool) error {
 	executable = strings.TrimSpace(executable)
 	if len(executable) == 0 {
 		if allowEmpty {
-			return executable, nil
+			return nil
 		}
-		return executable, fmt.Errorf("Need an executable to create a service for")
+		return fmt.Errorf("Need an executable to create a service for")
+	}
+	if err := validateSpecifiers(executable); err != nil {
+		return err
+	}
+
+	// only check the executable itself, not its prefixes and arguments
+	c, err := parseCommand(executable)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(c.Executable, "%") {
+		// the path depends on the instance and can't be checked here
+		return nil
+	}
+	path, err := resolveExecutable(c.Executable)
+	if err != nil {
+		return err
 	}
-	stat, err := os.Stat(executable)
+
+	stat, err := os.Stat(path)
 	if os.IsNotExist(err) {
-		return executable, fmt.Errorf("Could not find executable: %s is not a file", executable)
+		return fmt.Errorf("Could not find executable: %s is not a file", path)
+	}
+	if err != nil {
+		return fmt.Errorf("Could not find executable: %s", err)
 	}
 	if stat.IsDir() {
-		return executable, fmt.Errorf("Could not find executable: %s is a directory", executable)
+		return fmt.Errorf("Could not find executable: %s is a directory", path)
 	}
 	if stat.Mode()&0111 == 0 {
-		return executable, fmt.Errorf("%s is not executable", executable)
+		return fmt.Errorf("%s is not executable", path)
 	}
 
-	return executable, nil
+	return nil
 }
 
+// validated returns a function normalizing and validating the options before
+// calling submit.
+func validated(submit func() error) func() error {
+	return func() error {
+		normalizeOptions()
+		if err := validate(); err != nil {
+			return err
+		}
+		return submit()
+	}
+}
+
+// validate checks the options, which should have been normalized before.
 func validate() error {
-	ts, err := targets()
-	if err != nil {
-		return fmt.Errorf("Can't find systemd targets: %s", err)
+	if len(strings.TrimSpace(createOpts.Exec)) == 0 {
+		return fmt.Errorf("Need an executable to create a service for")
+	}
+	if len(createOpts.Description) == 0 {
+		return fmt.Errorf("Description for this service can't be empty")
+	}
+	if err := validateInstall(); err != nil {
+		return err
 	}
 
+	return validateOptions()
+}
+
+// validateOptions checks the options which are set, without requiring any.
+func validateOptions() error {
 	// Executable checks
-	createOpts.Exec, err = validateExecutables(createOpts.Exec, false)
-	if err != nil {
+	if err := validateExecutables(createOpts.Exec, true); err != nil {
 		return err
 	}
-	createOpts.ExecReload, err = validateExecutables(createOpts.ExecReload, true)
-	if err != nil {
+	if err := validateExecutables(createOpts.ExecReload, true); err != nil {
 		return err
 	}
-	createOpts.ExecStartPre, err = validateExecutables(createOpts.ExecStartPre, true)
-	if err != nil {
+	for _, hook := range commandHooks {
+		for _, c := range *hookCommands(hook) {
+			if err := validateExecutables(c, false); err != nil {
+				return fmt.Errorf("%s: %s", hook, err)
+			}
+		}
+	}
+
+	if err := validateAccounts(); err != nil {
 		return err
 	}
-	createOpts.ExecStartPost, err = validateExecutables(createOpts.ExecStartPost, true)
-	if err != nil {
+
+	if err := validateDependencies(); err != nil {
 		return err
 	}
-	createOpts.ExecStop, err = validateExecutables(createOpts.ExecStop, true)
-	if err != nil {
+	if err := validateTemplate(); err != nil {
 		return err
 	}
-	createOpts.ExecStopPost, err = validateExecutables(createOpts.ExecStopPost, true)
+	if err := validateResources(); err != nil {
+		return err
+	}
+	if err := validateEnvironment(); err != nil {
+		return err
+	}
+	if err := validateCredentials(); err != nil {
+		return err
+	}
+	if err := validateDirectories(); err != nil {
+		return err
+	}
+	if err := validateLogging(); err != nil {
+		return err
+	}
+	if err := validateHardening(); err != nil {
+		return err
+	}
+	if err := validateTimer(); err != nil {
+		return err
+	}
+	return validateSocket()
+}
+
+func validateTypes() error {
+	if len(createOpts.Type) > 0 && !types.Contains(strings.ToLower(createOpts.Type)) {
+		return fmt.Errorf("No such service type: %s", createOpts.Type)
+	}
+	if len(createOpts.Restart) > 0 && !restarts.Contains(strings.ToLower(createOpts.Restart)) {
+		return fmt.Errorf("No such restart type: %s", createOpts.Restart)
+	}
+
+	return nil
+}
+
+// serviceName returns the name of the generated units, derived from the
+// executable's file name unless set explicitly.
+func serviceName() string {
+	if len(createOpts.Name) > 0 {
+		return createOpts.Name
+	}
+
+	c, err := parseCommand(createOpts.Exec)
 	if err != nil {
+		return filepath.Base(createOpts.Exec)
+	}
+
+	return filepath.Base(c.Executable)
+}
+
+func executeCreate() error {
+	name := serviceName()
+	service := name + ".service"
+	if isTemplate() {
+		service = name + "@.service"
+	}
+
+	if dir := unitDir(); len(dir) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("Could not create unit directory: %s", err)
+		}
+	}
+
+	base := filepath.Join(unitDir(), name)
+	if err := importEnvFile(base); err != nil {
+		return err
+	}
+	if err := credentialEnvironment(base); err != nil {
+		return err
+	}
+	if err := writeUnit(filepath.Join(unitDir(), service), serviceOptions()); err != nil {
+		return err
+	}
+	if err := writeCompanionUnits(base); err != nil {
+		return err
+	}
+	if err := writeAccountFiles(name); err != nil {
 		return err
 	}
 
-	// Description check
-	if len(createOpts.Description) == 0 {
-		return fmt.Errorf("Description for this service can't be empty")
+	if createOpts.Install.Install {
+		return installUnits(name, service)
 	}
+	return nil
+}
 
-	// Target checks
-	if len(createOpts.After) > 0 && !ts.Contains(createOpts.After) {
-		return fmt.Errorf("Could not create service: no such target")
+// writeCompanionUnits writes the enabled timer and socket units next to a
+// service, base being the service's file name without any suffix.
+func writeCompanionUnits(base string) error {
+	if createOpts.Timer.Enabled {
+		if err := writeUnit(base+".timer", timerOptions()); err != nil {
+			return err
+		}
 	}
-	if len(createOpts.WantedBy) > 0 && !ts.Contains(createOpts.WantedBy) {
-		return fmt.Errorf("Could not create service: no such target")
+	if createOpts.Socket.Enabled {
+		if err := writeUnit(base+".socket", socketOptions()); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func executeCreate() error {
+// serviceOptions returns the non-empty unit options described by createOpts,
+// followed by any options loaded from an existing Unit file the generator
+// doesn't know about.
+func serviceOptions() []*unit.UnitOption {
 	u := []*unit.UnitOption{
 		&unit.UnitOption{"Unit", "Description", createOpts.Description},
 		&unit.UnitOption{"Unit", "After", createOpts.After},
+	}
+	u = append(u, dependencyOptions("Unit")...)
+
+	u = append(u, []*unit.UnitOption{
 
 		&unit.UnitOption{"Service", "Type", createOpts.Type},
+		&unit.UnitOption{"Service", "PIDFile", createOpts.PIDFile},
 		&unit.UnitOption{"Service", "WorkingDirectory", createOpts.WorkingDirectory},
 		&unit.UnitOption{"Service", "RootDirectory", createOpts.RootDirectory},
 
 		&unit.UnitOption{"Service", "ExecStart", createOpts.Exec},
-		&unit.UnitOption{"Service", "ExecStartPre", createOpts.ExecStartPre},
-		&unit.UnitOption{"Service", "ExecStartPost", createOpts.ExecStartPost},
-		&unit.UnitOption{"Service", "ExecReload", createOpts.ExecReload},
-		&unit.UnitOption{"Service", "ExecStop", createOpts.ExecStop},
-		&unit.UnitOption{"Service", "ExecStopPost", createOpts.ExecStopPost},
-
+	}...)
+	u = append(u, commandOptions("ExecStartPre")...)
+	u = append(u, commandOptions("ExecStartPost")...)
+	u = append(u, &unit.UnitOption{"Service", "ExecReload", createOpts.ExecReload})
+	u = append(u, commandOptions("ExecStop")...)
+	u = append(u, commandOptions("ExecStopPost")...)
+
+	u = append(u, []*unit.UnitOption{
 		&unit.UnitOption{"Service", "User", createOpts.User},
 		&unit.UnitOption{"Service", "Group", createOpts.Group},
 		&unit.UnitOption{"Service", "Restart", createOpts.Restart},
@@ -234,46 +440,125 @@ func executeCreate() error {
 		&unit.UnitOption{"Service", "TimeoutStartSec", createOpts.TimeoutStartSec},
 		&unit.UnitOption{"Service", "TimeoutStopSec", createOpts.TimeoutStopSec},
 
-		&unit.UnitOption{"Install", "WantedBy", createOpts.WantedBy},
+		&unit.UnitOption{"Service", "Slice", createOpts.Slice},
+		&unit.UnitOption{"Service", "MemoryMax", createOpts.MemoryMax},
+		&unit.UnitOption{"Service", "MemoryHigh", createOpts.MemoryHigh},
+		&unit.UnitOption{"Service", "CPUQuota", createOpts.CPUQuota},
+		&unit.UnitOption{"Service", "CPUWeight", createOpts.CPUWeight},
+		&unit.UnitOption{"Service", "IOWeight", createOpts.IOWeight},
+		&unit.UnitOption{"Service", "TasksMax", createOpts.TasksMax},
+		&unit.UnitOption{"Service", "LimitNOFILE", createOpts.LimitNOFILE},
+	}...)
+	if createOpts.DynamicUser {
+		u = append(u, &unit.UnitOption{"Service", "DynamicUser", strconv.FormatBool(createOpts.DynamicUser)})
+	}
+
+	u = append(u, directoryOptions()...)
+	u = append(u, environmentOptions()...)
+	u = append(u, credentialOptions()...)
+	u = append(u, loggingOptions()...)
+	u = append(u, hardeningOptions()...)
+	u = append(u, &unit.UnitOption{"Install", "WantedBy", createOpts.WantedBy})
+	u = append(u, dependencyOptions("Install")...)
+
+	return append(stripEmptyOptions(u), extraOptions...)
+}
+
+// hookCommands returns the CreateOptions field holding the commands of a
+// command hook.
+func hookCommands(hook string) *[]string {
+	switch hook {
+	case "ExecStartPre":
+		return &createOpts.ExecStartPre
+	case "ExecStartPost":
+		return &createOpts.ExecStartPost
+	case "ExecStop":
+		return &createOpts.ExecStop
+	case "ExecStopPost":
+		return &createOpts.ExecStopPost
+	default:
+		return nil
 	}
+}
+
+// commandOptions returns one unit option per command of a hook.
+func commandOptions(hook string) []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, c := range *hookCommands(hook) {
+		u = append(u, &unit.UnitOption{"Service", hook, c})
+	}
+
+	return u
+}
 
-	r := unit.Serialize(stripEmptyOptions(u))
+func writeUnit(filename string, opts []*unit.UnitOption) error {
+	r := unit.Serialize(opts)
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		return fmt.Errorf("Encountered error while reading output: %v", err)
 	}
 
-	filename := filepath.Base(createOpts.Exec) + ".service"
-	f, err := os.Create(filename)
+	changed, err := writeFile(filename, b, 0644)
 	if err != nil {
-		return fmt.Errorf("Could not create file: %s", err)
+		return err
+	}
+	if !changed {
+		fmt.Printf("Unit file unchanged: %s\n", filename)
+		return nil
+	}
+
+	fmt.Printf("Generated Unit file: %s\n%s\n", filename, b)
+	return nil
+}
+
+// writeFile writes a generated file with the given permissions, unless it
+// already exists with the same content and permissions. It returns whether the
+// file changed.
+func writeFile(filename string, b []byte, perm os.FileMode) (bool, error) {
+	if old, err := ioutil.ReadFile(filename); err == nil && bytes.Equal(old, b) {
+		if fi, err := os.Stat(filename); err == nil && fi.Mode().Perm() == perm {
+			return false, nil
+		}
+	}
+
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return false, fmt.Errorf("Could not create file: %s", err)
 	}
 	defer f.Close()
 
+	// an existing file keeps its permissions when opened
+	if err := f.Chmod(perm); err != nil {
+		return false, fmt.Errorf("Could not change file permissions: %s", err)
+	}
+
 	_, err = f.Write(b)
 	if err != nil {
-		return fmt.Errorf("Could not write to file: %s", err)
+		return false, fmt.Errorf("Could not write to file: %s", err)
 	}
 
-	fmt.Printf("Generated Unit file: %s\n%s\n", filename, b)
-	return nil
+	changedFiles = append(changedFiles, filename)
+	return true, nil
 }
 
 func init() {
-	createCmd.PersistentFlags().StringVarP(&createOpts.Type, "type", "t", "simple", "Type of service (simple, forking, oneshot, dbus, notify or idle)")
+	createCmd.PersistentFlags().StringVarP(&createOpts.Name, "name", "n", "", "Name of the service (default is the executable's file name)")
+	createCmd.PersistentFlags().StringVarP(&createOpts.Type, "type", "t", "simple", "Type of service ("+types.Alternatives()+")")
+
+	createCmd.PersistentFlags().StringVar(&createOpts.PIDFile, "pidfile", "", "PID file of forking services")
 
-	createCmd.PersistentFlags().StringVar(&createOpts.ExecStartPre, "execstartpre", "", "Executable to run before the service starts")
-	createCmd.PersistentFlags().StringVar(&createOpts.ExecStartPost, "execstartpost", "", "Executable to run after the service started")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.ExecStartPre, "execstartpre", nil, "Executable to run before the service starts (repeatable)")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.ExecStartPost, "execstartpost", nil, "Executable to run after the service started (repeatable)")
 	createCmd.PersistentFlags().StringVar(&createOpts.ExecReload, "execreload", "", "Executable to run to reload the service")
-	createCmd.PersistentFlags().StringVar(&createOpts.ExecStop, "execstop", "", "Executable to run to stop the service")
-	createCmd.PersistentFlags().StringVar(&createOpts.ExecStopPost, "execstoppost", "", "Executable to run after the service stopped")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.ExecStop, "execstop", nil, "Executable to run to stop the service (repeatable)")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.ExecStopPost, "execstoppost", nil, "Executable to run after the service stopped (repeatable)")
 
 	createCmd.PersistentFlags().StringVarP(&createOpts.WorkingDirectory, "workingdir", "w", "", "Working-directory of the service")
 	createCmd.PersistentFlags().StringVar(&createOpts.RootDirectory, "rootdir", "", "Root-directory of the service")
 	createCmd.PersistentFlags().StringVarP(&createOpts.User, "user", "u", "root", "User to run service as")
 	createCmd.PersistentFlags().StringVarP(&createOpts.Group, "group", "g", "root", "Group to run service as")
 
-	createCmd.PersistentFlags().StringVarP(&createOpts.Restart, "restart", "r", "on-failure", "When to restart (no, always, on-success, on-failure, on-abnormal, on-abort or on-watchdog)")
+	createCmd.PersistentFlags().StringVarP(&createOpts.Restart, "restart", "r", "on-failure", "When to restart ("+restarts.Alternatives()+")")
 	createCmd.PersistentFlags().StringVarP(&createOpts.RestartSec, "restartsec", "s", "", "How many seconds between restarts")
 	createCmd.PersistentFlags().StringVar(&createOpts.TimeoutStartSec, "timeoutstartsec", "", "How many seconds to wait for a startup")
 	createCmd.PersistentFlags().StringVar(&createOpts.TimeoutStopSec, "timeoutstopsec", "", "How many seconds to wait when stoping a service")
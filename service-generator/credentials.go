@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+var (
+	// secretEnv is what happens to environment variables which look like
+	// secrets: a warning, refusing them, or moving them into credentials
+	secretEnv        string
+	secretEnvActions = Strings{"warn", "refuse", "credential"}
+
+	// secretKey matches the names of environment variables which usually
+	// hold secrets
+	secretKey = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+
+	// SetCredential values are C-escaped, so multi-line secrets like PEM
+	// keys fit on a single line
+	credentialEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "%", "%%")
+	credentialUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\t`, "\t", `\r`, "\r", "%%", "%")
+)
+
+// looksSecret returns whether a KEY=VALUE assignment seems to hold a secret.
+func looksSecret(kv string) bool {
+	k, v, err := splitEnv(kv)
+	// paths only tell where a secret is kept, like SECRETS_DIR=/etc/app
+	if err != nil || len(v) == 0 || filepath.IsAbs(v) {
+		return false
+	}
+
+	return secretKey.MatchString(k) || strings.HasPrefix(v, "-----BEGIN ")
+}
+
+// splitSecrets separates the assignments which look like secrets from the
+// others.
+func splitSecrets(kvs []string) ([]string, []string) {
+	var env, secrets []string
+	for _, kv := range kvs {
+		if looksSecret(kv) {
+			secrets = append(secrets, kv)
+		} else {
+			env = append(env, kv)
+		}
+	}
+
+	return env, secrets
+}
+
+// splitCredential splits a NAME:VALUE credential into its name and value.
+// LoadCredential may omit the value, systemd then loads the credential of
+// the same name from the credential store.
+func splitCredential(flag, c string) (string, string, error) {
+	name, value := c, ""
+	if i := strings.Index(c, ":"); i >= 0 {
+		name, value = c[:i], c[i+1:]
+	} else if flag != "LoadCredential" {
+		return "", "", fmt.Errorf("Credential needs to be of the form NAME:VALUE for %s: %s", flag, c)
+	}
+	if len(name) == 0 || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("Invalid credential name for %s: %s", flag, name)
+	}
+
+	return name, value, nil
+}
+
+// withoutCredential returns the credentials except the one named name.
+func withoutCredential(flag string, creds []string, name string) []string {
+	var res []string
+	for _, c := range creds {
+		if n, _, _ := splitCredential(flag, c); n != name {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// moveSecrets writes the variables which look like secrets to private files
+// in base.credentials if requested, and loads them as credentials which the
+// service can read from $CREDENTIALS_DIRECTORY. It returns the remaining
+// variables.
+func moveSecrets(base string, kvs []string) ([]string, error) {
+	env, secrets := splitSecrets(kvs)
+	if secretEnv != "credential" || len(secrets) == 0 {
+		return kvs, nil
+	}
+
+	dir, err := filepath.Abs(base + ".credentials")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("Could not create credentials directory: %s", err)
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, fmt.Errorf("Could not create credentials directory: %s", err)
+	}
+
+	for _, kv := range secrets {
+		k, v, _ := splitEnv(kv)
+		filename := filepath.Join(dir, k)
+		changed, err := writeFile(filename, []byte(v), 0600)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			fmt.Printf("Generated credential file: %s\n", filename)
+		} else {
+			fmt.Printf("Credential file unchanged: %s\n", filename)
+		}
+
+		// a moved variable replaces the credential of the same name
+		createOpts.SetCredentials = withoutCredential("SetCredential", createOpts.SetCredentials, k)
+		createOpts.LoadCredentials = append(withoutCredential("LoadCredential", createOpts.LoadCredentials, k), k+":"+filename)
+	}
+
+	return env, nil
+}
+
+// credentialEnvironment moves the service's variables which look like secrets
+// into credential files next to base, if requested.
+func credentialEnvironment(base string) error {
+	env, err := moveSecrets(base, createOpts.Environment)
+	if err != nil {
+		return err
+	}
+
+	createOpts.Environment = env
+	return nil
+}
+
+// checkSecrets warns about or refuses variables which look like secrets.
+func checkSecrets(kvs []string) error {
+	if !secretEnvActions.Contains(secretEnv) {
+		return fmt.Errorf("No such secret-env action: %s (expected one of %s)", secretEnv, strings.Join(secretEnvActions, ", "))
+	}
+	if secretEnv == "credential" {
+		// they get moved when the unit gets written
+		return nil
+	}
+
+	_, secrets := splitSecrets(kvs)
+	for _, kv := range secrets {
+		k, _, _ := splitEnv(kv)
+		if secretEnv == "refuse" {
+			return fmt.Errorf("Environment variable %s looks like a secret: pass it with --set-credential %s:VALUE or --credential %s:/path instead, or use --secret-env credential to move it", k, k, k)
+		}
+		fmt.Fprintf(os.Stderr, "WARNING: environment variable %s looks like a secret and is visible to anyone who can inspect the service, use --secret-env credential to move it into a credential\n", k)
+	}
+
+	return nil
+}
+
+// secureEnvironment moves or checks the variables which look like secrets,
+// for units which get written without being validated, like imported ones.
+func secureEnvironment(base string) error {
+	if err := credentialEnvironment(base); err != nil {
+		return err
+	}
+	return checkSecrets(createOpts.Environment)
+}
+
+func validateCredentials() error {
+	if err := checkSecrets(createOpts.Environment); err != nil {
+		return err
+	}
+	if len(createOpts.EnvImport) > 0 {
+		kvs, err := readEnvFile(createOpts.EnvImport)
+		if err != nil {
+			return err
+		}
+		if err := checkSecrets(kvs); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range createOpts.LoadCredentials {
+		if _, path, err := splitCredential("LoadCredential", c); err != nil {
+			return err
+		} else if len(path) == 0 && strings.HasSuffix(c, ":") {
+			return fmt.Errorf("LoadCredential needs a path after the colon: %s", c)
+		}
+	}
+	for _, c := range createOpts.SetCredentials {
+		if _, _, err := splitCredential("SetCredential", c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func credentialOptions() []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, c := range createOpts.LoadCredentials {
+		u = append(u, &unit.UnitOption{"Service", "LoadCredential", c})
+	}
+	for _, c := range createOpts.SetCredentials {
+		u = append(u, &unit.UnitOption{"Service", "SetCredential", credentialEscaper.Replace(c)})
+	}
+
+	return u
+}
+
+func init() {
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.LoadCredentials, "credential", nil, "Credential to load from a file (NAME:PATH) or the credential store (NAME, repeatable)")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.SetCredentials, "set-credential", nil, "Credential to pass to the service (NAME:VALUE, repeatable)")
+	RootCmd.PersistentFlags().StringVar(&secretEnv, "secret-env", "warn", "What to do with environment variables that look like secrets (warn, refuse or credential)")
+}
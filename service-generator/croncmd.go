@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type cronField struct {
+	Min, Max int
+	Names    Strings
+}
+
+var (
+	cronSystem bool
+	cronUser   string
+
+	cronMinute  = cronField{0, 59, nil}
+	cronHour    = cronField{0, 23, nil}
+	cronDay     = cronField{1, 31, nil}
+	cronMonth   = cronField{1, 12, Strings{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekday = cronField{0, 7, Strings{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+
+	// cronShorthands maps cron's special schedules to their regular form
+	cronShorthands = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+
+	// cronSpools are the directories cron keeps the users' crontabs in,
+	// named after their owner
+	cronSpools = Strings{"/var/spool/cron", "/var/spool/cron/crontabs", "/var/spool/cron/tabs"}
+
+	cronCmd = &cobra.Command{
+		Use:   "from-cron <crontab>",
+		Short: "creates services and timers from a crontab",
+		Long: `The from-cron command creates a service and a timer for each job of a crontab.
+Files in /etc/cron.d and /etc/crontab have an additional user column, which
+is detected from their path or can be enforced with --system. The jobs of a
+user crontab run as its owner, which is detected from its path in the cron
+spool or needs to be given with --user`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename := args[0]
+			system := cronSystem || filename == "/etc/crontab" || filepath.Dir(filename) == "/etc/cron.d"
+
+			user := cronUser
+			if !system && len(user) == 0 {
+				// jobs of personal crontabs must not end up running as root
+				if !cronSpools.Contains(filepath.Dir(filename)) {
+					return fmt.Errorf("Can't tell whose crontab %s is, pass its owner with --user", filename)
+				}
+				user = filepath.Base(filename)
+			}
+
+			jobs, err := importCrontab(filename, system, user)
+			if err != nil {
+				return err
+			}
+			if len(jobs) == 0 {
+				return fmt.Errorf("Could not find any jobs in %s", filename)
+			}
+
+			for _, s := range jobs {
+				createOpts = s.Options
+				extraOptions = nil
+				if err := secureEnvironment(s.Name); err != nil {
+					return fmt.Errorf("%s: %s", s.Name, err)
+				}
+
+				if err := writeUnit(s.Name+".service", serviceOptions()); err != nil {
+					return err
+				}
+				if err := writeCompanionUnits(s.Name); err != nil {
+					return err
+				}
+				if len(s.Untranslated) > 0 {
+					fmt.Printf("Could not translate exactly for %s:\n", s.Name)
+					for _, u := range s.Untranslated {
+						fmt.Printf("  - %s\n", u)
+					}
+					fmt.Println()
+				}
+			}
+
+			return nil
+		},
+	}
+)
+
+// parse returns the sorted values of a cron field like "1-5", "*/15" or
+// "mon,wed,fri".
+func (f cronField) parse(s string) ([]int, error) {
+	value := func(v string) (int, error) {
+		if i := f.Names.IndexOf(strings.ToLower(v)); i >= 0 {
+			return i + f.Min, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < f.Min || n > f.Max {
+			return 0, fmt.Errorf("Invalid value in cron schedule: %s", v)
+		}
+		return n, nil
+	}
+
+	set := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if rep := strings.SplitN(part, "/", 2); len(rep) == 2 {
+			n, err := strconv.Atoi(rep[1])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("Invalid step in cron schedule: %s", part)
+			}
+			part, step = rep[0], n
+		}
+
+		from, to := f.Min, f.Max
+		if part != "*" {
+			r := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = value(r[0]); err != nil {
+				return nil, err
+			}
+			switch {
+			case len(r) == 2:
+				if to, err = value(r[1]); err != nil {
+					return nil, err
+				}
+			case step == 1:
+				to = from
+			}
+			if from > to {
+				return nil, fmt.Errorf("Invalid range in cron schedule: %s", part)
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+
+	var res []int
+	for v := range set {
+		res = append(res, v)
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// calendarComponent formats values as a systemd calendar component, using
+// repetitions and ranges where possible.
+func (f cronField) calendarComponent(values []int, format string) string {
+	if len(values) == f.Max-f.Min+1 {
+		return "*"
+	}
+
+	if len(values) > 2 {
+		step := values[1] - values[0]
+		repeated := step > 1 && values[len(values)-1]+step > f.Max
+		for i := 1; i < len(values) && repeated; i++ {
+			repeated = values[i]-values[i-1] == step
+		}
+		if repeated {
+			return fmt.Sprintf(format+"/%d", values[0], step)
+		}
+	}
+
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, fmt.Sprintf(format+".."+format, values[i], values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, fmt.Sprintf(format, values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// calendarWeekdays formats cron weekdays (0 and 7 being Sunday) as a systemd
+// weekday specification.
+func calendarWeekdays(values []int) string {
+	set := map[int]bool{}
+	for _, v := range values {
+		// systemd's week starts on Monday
+		set[(v+6)%7] = true
+	}
+	if len(set) == 7 {
+		return ""
+	}
+
+	var parts []string
+	for i := 0; i < 7; {
+		if !set[i] {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < 7 && set[j+1] {
+			j++
+		}
+		day := strings.Title(weekdays[i])
+		switch {
+		case j-i >= 2:
+			parts = append(parts, day+".."+strings.Title(weekdays[j]))
+		case j > i:
+			parts = append(parts, day, strings.Title(weekdays[j]))
+		default:
+			parts = append(parts, day)
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// cronCalendar translates the five fields of a cron schedule into a systemd
+// calendar event expression.
+func cronCalendar(s *importedService, fields []string) (string, error) {
+	minutes, err := cronMinute.parse(fields[0])
+	if err != nil {
+		return "", err
+	}
+	hours, err := cronHour.parse(fields[1])
+	if err != nil {
+		return "", err
+	}
+	days, err := cronDay.parse(fields[2])
+	if err != nil {
+		return "", err
+	}
+	months, err := cronMonth.parse(fields[3])
+	if err != nil {
+		return "", err
+	}
+	dows, err := cronWeekday.parse(fields[4])
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*") {
+		// cron runs the job when either the day of month or the weekday
+		// matches, systemd only when both do
+		s.untranslated("%s %s runs on either the day of month or the weekday in cron, the timer only fires when both match", fields[2], fields[4])
+	}
+
+	calendar := fmt.Sprintf("*-%s-%s %s:%s:00",
+		cronMonth.calendarComponent(months, "%02d"),
+		cronDay.calendarComponent(days, "%02d"),
+		cronHour.calendarComponent(hours, "%02d"),
+		cronMinute.calendarComponent(minutes, "%02d"))
+	if wd := calendarWeekdays(dows); len(wd) > 0 {
+		calendar = wd + " " + calendar
+	}
+
+	return calendar, validateCalendar(calendar)
+}
+
+// cronCommand returns the command of a cron job, which cron runs with a
+// shell.
+func cronCommand(s *importedService, shell, command string) string {
+	// an unescaped % ends the command, the rest is fed to its stdin
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		switch {
+		case command[i] == '\\' && i+1 < len(command) && command[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case command[i] == '%':
+			s.untranslated("Input passed with %% can't be translated: %s", command[i+1:])
+			i = len(command)
+		default:
+			b.WriteByte(command[i])
+		}
+	}
+
+	// leave expanding variables to the shell
+	escaped := strings.NewReplacer("$", "$$", "%", "%%").Replace(strings.TrimSpace(b.String()))
+	return joinCommand([]string{shell, "-c", escaped})
+}
+
+// importCrontab translates the jobs of a crontab into services with timers.
+// System crontabs name the user to run a job as in their sixth column, the
+// jobs of other crontabs run as user.
+func importCrontab(filename string, system bool, user string) ([]importedService, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	shell := "/bin/sh"
+	var env []string
+	var mailto string
+
+	var res []importedService
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Variables
+		if m := shellAssignment.FindStringSubmatch(strings.Replace(line, " = ", "=", 1)); m != nil && !strings.ContainsAny(m[1], " \t") {
+			value := strings.Trim(strings.TrimSpace(m[2]), `"'`)
+			switch m[1] {
+			case "SHELL":
+				shell = value
+			case "MAILTO":
+				mailto = value
+			default:
+				env = append(env, m[1]+"="+value)
+			}
+			continue
+		}
+
+		s := newImportedService(fmt.Sprintf("%s-%d", base, len(res)+1))
+		s.Options.Type = "oneshot"
+		s.Options.Restart = ""
+		s.Options.WantedBy = ""
+		s.Options.User = user
+		s.Options.Environment = append([]string{}, env...)
+		s.Options.Timer.Enabled = true
+		if len(mailto) > 0 {
+			s.untranslated("MAILTO=%s: the output is logged to the journal instead of being mailed", mailto)
+		}
+
+		// Schedule and user columns
+		fields := strings.Fields(line)
+		columns := 5
+		if strings.HasPrefix(fields[0], "@") {
+			columns = 1
+		}
+		if system {
+			columns++
+		}
+		if len(fields) <= columns {
+			return nil, fmt.Errorf("Invalid crontab line %d: %s", n, line)
+		}
+		if system {
+			s.Options.User = fields[columns-1]
+		}
+		command := line
+		for i := 0; i < columns; i++ {
+			command = strings.TrimSpace(command[len(strings.Fields(command)[0]):])
+		}
+
+		var schedule []string
+		if strings.HasPrefix(fields[0], "@") {
+			special := strings.ToLower(fields[0])
+			shorthand, ok := cronShorthands[special]
+			switch {
+			case special == "@reboot":
+				s.untranslated("@reboot runs the job when cron starts, the timer fires right after boot instead")
+				s.Options.Timer.OnBootSec = "0"
+				schedule = nil
+			case !ok:
+				return nil, fmt.Errorf("Invalid crontab line %d: unknown schedule %s", n, fields[0])
+			default:
+				schedule = strings.Fields(shorthand)
+			}
+		} else {
+			schedule = fields[:5]
+		}
+		if len(schedule) > 0 {
+			calendar, err := cronCalendar(&s, schedule)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid crontab line %d: %s", n, err)
+			}
+			s.Options.Timer.OnCalendar = calendar
+		}
+
+		s.Options.Exec = cronCommand(&s, shell, command)
+		s.Options.Description = fmt.Sprintf("%s cron job: %s", base, strings.Replace(command, "%", "%%", -1))
+
+		res = append(res, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	return res, nil
+}
+
+func init() {
+	cronCmd.PersistentFlags().BoolVar(&cronSystem, "system", false, "Read a system crontab, which names the user of each job")
+	cronCmd.PersistentFlags().StringVar(&cronUser, "user", "", "User to run the jobs of a user crontab as (default is the owner of a crontab in the cron spool)")
+
+	RootCmd.AddCommand(cronCmd)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCronCalendar(t *testing.T) {
+	tests := []struct {
+		schedule     string
+		want         string
+		untranslated bool
+		err          bool
+	}{
+		{schedule: "* * * * *", want: "*-*-* *:*:00"},
+		{schedule: "0 3 * * *", want: "*-*-* 03:00:00"},
+		{schedule: "*/15 * * * *", want: "*-*-* *:00/15:00"},
+		{schedule: "0 */6 * * *", want: "*-*-* 00/6:00:00"},
+		{schedule: "30 2 * * 1-5", want: "Mon..Fri *-*-* 02:30:00"},
+		{schedule: "0 9 * * mon,wed,fri", want: "Mon,Wed,Fri *-*-* 09:00:00"},
+		{schedule: "0 12 * * 0,6", want: "Sat,Sun *-*-* 12:00:00"},
+		{schedule: "0 0 * * 7", want: "Sun *-*-* 00:00:00"},
+		{schedule: "0 0 1 1 *", want: "*-01-01 00:00:00"},
+		{schedule: "0 0 1 jan-mar *", want: "*-01..03-01 00:00:00"},
+		{schedule: "5,10 8-17 * * *", want: "*-*-* 08..17:05,10:00"},
+		{schedule: "0 0 1 * 1", want: "Mon *-*-01 00:00:00", untranslated: true},
+		{schedule: "60 * * * *", err: true},
+		{schedule: "* 24 * * *", err: true},
+		{schedule: "* * 0 * *", err: true},
+		{schedule: "* * * 13 *", err: true},
+		{schedule: "* * * * 8", err: true},
+		{schedule: "*/0 * * * *", err: true},
+		{schedule: "5-1 * * * *", err: true},
+		{schedule: "* * * foo *", err: true},
+	}
+
+	for _, test := range tests {
+		s := newImportedService("test")
+		calendar, err := cronCalendar(&s, strings.Fields(test.schedule))
+		if test.err {
+			if err == nil {
+				t.Errorf("cronCalendar(%q): expected an error, got %s", test.schedule, calendar)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("cronCalendar(%q): %s", test.schedule, err)
+			continue
+		}
+		if calendar != test.want {
+			t.Errorf("cronCalendar(%q) = %s, expected %s", test.schedule, calendar, test.want)
+		}
+		if untranslated := len(s.Untranslated) > 0; untranslated != test.untranslated {
+			t.Errorf("cronCalendar(%q) reported untranslated: %v", test.schedule, s.Untranslated)
+		}
+	}
+}
+
+func TestImportCrontab(t *testing.T) {
+	jobs, err := importCrontab("testdata/cron/alice", false, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		exec       string
+		onCalendar string
+		onBootSec  string
+	}{
+		{"alice-1", `/bin/bash -c "/usr/local/bin/backup --all"`, "*-*-* 03:00:00", ""},
+		{"alice-2", `/bin/bash -c /usr/bin/warmup`, "", "0"},
+		{"alice-3", `/bin/bash -c "echo \"50%% done\" > /tmp/progress"`, "Mon..Fri *-*-* 09..17:00/15:00", ""},
+	}
+	if len(jobs) != len(tests) {
+		t.Fatalf("Expected %d jobs, got %d", len(tests), len(jobs))
+	}
+	for i, test := range tests {
+		s := jobs[i]
+		if s.Name != test.name {
+			t.Errorf("Expected job %s, got %s", test.name, s.Name)
+		}
+		if s.Options.Exec != test.exec {
+			t.Errorf("%s: expected command %s, got %s", test.name, test.exec, s.Options.Exec)
+		}
+		if s.Options.Timer.OnCalendar != test.onCalendar || s.Options.Timer.OnBootSec != test.onBootSec {
+			t.Errorf("%s: expected schedule %q/%q, got %q/%q", test.name, test.onCalendar, test.onBootSec, s.Options.Timer.OnCalendar, s.Options.Timer.OnBootSec)
+		}
+		if s.Options.User != "alice" || s.Options.Type != "oneshot" || !s.Options.Timer.Enabled {
+			t.Errorf("%s: expected a oneshot service with a timer running as alice, got %+v", test.name, s.Options)
+		}
+		if len(s.Options.Environment) != 1 || s.Options.Environment[0] != "PATH=/usr/local/bin:/usr/bin:/bin" {
+			t.Errorf("%s: expected the crontab's PATH, got %q", test.name, s.Options.Environment)
+		}
+	}
+	if len(jobs[1].Untranslated) == 0 {
+		t.Errorf("Expected @reboot to be reported as untranslated")
+	}
+}
+
+func TestImportSystemCrontab(t *testing.T) {
+	jobs, err := importCrontab("testdata/cron/certbot", true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected 1 job, got %d", len(jobs))
+	}
+
+	s := jobs[0]
+	if s.Options.User != "root" {
+		t.Errorf("Expected the job to run as root, got %q", s.Options.User)
+	}
+	if s.Options.Exec != `/bin/sh -c "certbot -q renew"` {
+		t.Errorf("Unexpected command: %s", s.Options.Exec)
+	}
+	if s.Options.Timer.OnCalendar != "*-*-* 00,12:00:00" {
+		t.Errorf("Unexpected schedule: %s", s.Options.Timer.OnCalendar)
+	}
+	if len(s.Untranslated) == 0 {
+		t.Errorf("Expected MAILTO to be reported as untranslated")
+	}
+}
+
+func TestImportInvalidCrontab(t *testing.T) {
+	if _, err := importCrontab("testdata/cron/invalid", false, "alice"); err == nil {
+		t.Errorf("Expected an error for a job without a command")
+	}
+	if _, err := importCrontab("testdata/cron/missing", false, "alice"); err == nil {
+		t.Errorf("Expected an error for a missing crontab")
+	}
+}
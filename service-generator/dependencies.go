@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type dependency struct {
+	Section string
+	Name    string
+	Usage   string
+}
+
+// dependencies are the dependency directives besides After and WantedBy, in
+// the order they get written.
+var dependencies = []dependency{
+	{"Unit", "Requires", "Units which must be started along with the service"},
+	{"Unit", "Wants", "Units which should be started along with the service"},
+	{"Unit", "BindsTo", "Units the service gets stopped along with"},
+	{"Unit", "PartOf", "Units whose stops and restarts propagate to the service"},
+	{"Unit", "Conflicts", "Units which can't run at the same time as the service"},
+	{"Unit", "Before", "Units to start after the service"},
+	{"Install", "RequiredBy", "Units which require the service once it's enabled"},
+	{"Install", "Also", "Units to enable and disable along with the service"},
+}
+
+// dependencyField returns the CreateOptions field holding a dependency
+// directive.
+func dependencyField(name string) *string {
+	switch name {
+	case "After":
+		return &createOpts.After
+	case "WantedBy":
+		return &createOpts.WantedBy
+	case "Requires":
+		return &createOpts.Requires
+	case "Wants":
+		return &createOpts.Wants
+	case "BindsTo":
+		return &createOpts.BindsTo
+	case "PartOf":
+		return &createOpts.PartOf
+	case "Conflicts":
+		return &createOpts.Conflicts
+	case "Before":
+		return &createOpts.Before
+	case "RequiredBy":
+		return &createOpts.RequiredBy
+	case "Also":
+		return &createOpts.Also
+	default:
+		return nil
+	}
+}
+
+// knownUnit returns whether name is one of the known units or an instance of
+// a known template.
+func knownUnit(us Strings, name string) bool {
+	if us.Contains(name) {
+		return true
+	}
+
+	at := strings.Index(name, "@")
+	if at < 0 {
+		return false
+	}
+	return us.Contains(name[:at+1] + filepath.Ext(name))
+}
+
+// validateDependencies checks that all units the service depends on exist.
+// The units generated along with the service count as known.
+func validateDependencies() error {
+	us, err := units()
+	if err != nil {
+		return fmt.Errorf("Can't find systemd units: %s", err)
+	}
+	name := serviceName()
+	us = append(us, name+".service", name+"@.service", name+".timer", name+".socket")
+
+	names := []string{"After", "WantedBy"}
+	for _, d := range dependencies {
+		names = append(names, d.Name)
+	}
+	for _, n := range names {
+		for _, u := range strings.Fields(*dependencyField(n)) {
+			if strings.Contains(u, "%") {
+				// depends on the instance
+				continue
+			}
+			if !knownUnit(us, u) {
+				return fmt.Errorf("Could not create service: no such unit %s for %s", u, n)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dependencyOptions returns the dependency options of a section.
+func dependencyOptions(section string) []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, d := range dependencies {
+		if d.Section == section {
+			u = append(u, &unit.UnitOption{d.Section, d.Name, *dependencyField(d.Name)})
+		}
+	}
+
+	return u
+}
+
+func init() {
+	for _, d := range dependencies {
+		createCmd.PersistentFlags().StringVar(dependencyField(d.Name), strings.ToLower(d.Name), "", d.Usage+" (space separated)")
+	}
+}
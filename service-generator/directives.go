@@ -0,0 +1,226 @@
+package main
+
+import (
+	"strings"
+)
+
+var (
+	unitDirectives = Strings{
+		"Description", "Documentation", "Wants", "Requires", "Requisite", "BindsTo", "PartOf", "Upholds",
+		"Conflicts", "Before", "After", "OnFailure", "OnSuccess", "PropagatesReloadTo", "ReloadPropagatedFrom",
+		"PropagatesStopTo", "StopPropagatedFrom", "JoinsNamespaceOf", "RequiresMountsFor", "OnFailureJobMode",
+		"IgnoreOnIsolate", "StopWhenUnneeded", "RefuseManualStart", "RefuseManualStop", "AllowIsolate",
+		"DefaultDependencies", "CollectMode", "FailureAction", "SuccessAction", "FailureActionExitStatus",
+		"SuccessActionExitStatus", "JobTimeoutSec", "JobRunningTimeoutSec", "JobTimeoutAction",
+		"JobTimeoutRebootArgument", "StartLimitIntervalSec", "StartLimitInterval", "StartLimitBurst",
+		"StartLimitAction", "RebootArgument", "SourcePath",
+	}
+	// conditions may be used as ConditionFoo= or AssertFoo=
+	conditions = Strings{
+		"Architecture", "Firmware", "Virtualization", "Host", "KernelCommandLine", "KernelVersion",
+		"Environment", "Security", "Capability", "ACPower", "NeedsUpdate", "FirstBoot", "PathExists",
+		"PathExistsGlob", "PathIsDirectory", "PathIsSymbolicLink", "PathIsMountPoint", "PathIsReadWrite",
+		"PathIsEncrypted", "DirectoryNotEmpty", "FileNotEmpty", "FileIsExecutable", "User", "Group",
+		"ControlGroupController", "Memory", "CPUs", "CPUFeature", "OSRelease", "MemoryPressure",
+		"CPUPressure", "IOPressure",
+	}
+	installDirectives = Strings{"Alias", "WantedBy", "RequiredBy", "UpheldBy", "Also", "DefaultInstance"}
+
+	serviceDirectives = Strings{
+		"Type", "ExitType", "RemainAfterExit", "GuessMainPID", "PIDFile", "BusName", "ExecStart",
+		"ExecStartPre", "ExecStartPost", "ExecCondition", "ExecReload", "ExecStop", "ExecStopPost",
+		"RestartSec", "RestartSteps", "RestartMaxDelaySec", "TimeoutStartSec", "TimeoutStopSec",
+		"TimeoutAbortSec", "TimeoutSec", "TimeoutStartFailureMode", "TimeoutStopFailureMode", "RuntimeMaxSec",
+		"RuntimeRandomizedExtraSec", "WatchdogSec", "Restart", "RestartMode", "SuccessExitStatus",
+		"RestartPreventExitStatus", "RestartForceExitStatus", "RootDirectoryStartOnly", "NonBlocking",
+		"NotifyAccess", "Sockets", "FileDescriptorStoreMax", "FileDescriptorStorePreserve",
+		"USBFunctionDescriptors", "USBFunctionStrings", "OOMPolicy", "OpenFile", "ReloadSignal",
+		"PermissionsStartOnly",
+	}
+	socketDirectives = Strings{
+		"ListenStream", "ListenDatagram", "ListenSequentialPacket", "ListenFIFO", "ListenSpecial",
+		"ListenNetlink", "ListenMessageQueue", "ListenUSBFunction", "SocketProtocol", "BindIPv6Only",
+		"Backlog", "BindToDevice", "SocketUser", "SocketGroup", "SocketMode", "DirectoryMode", "Accept",
+		"Writable", "FlushPending", "MaxConnections", "MaxConnectionsPerSource", "KeepAlive",
+		"KeepAliveTimeSec", "KeepAliveIntervalSec", "KeepAliveProbes", "NoDelay", "Priority",
+		"DeferAcceptSec", "ReceiveBuffer", "SendBuffer", "IPTOS", "IPTTL", "Mark", "ReusePort", "SmackLabel",
+		"SmackLabelIPIn", "SmackLabelIPOut", "SELinuxContextFromNet", "PipeSize", "MessageQueueMaxMessages",
+		"MessageQueueMessageSize", "FreeBind", "Transparent", "Broadcast", "PassCredentials", "PassSecurity",
+		"PassPacketInfo", "Timestamping", "TCPCongestion", "ExecStartPre", "ExecStartPost", "ExecStopPre",
+		"ExecStopPost", "TimeoutSec", "Service", "RemoveOnStop", "Symlinks", "FileDescriptorName",
+		"TriggerLimitIntervalSec", "TriggerLimitBurst", "PollLimitIntervalSec", "PollLimitBurst",
+	}
+	timerDirectives = Strings{
+		"OnActiveSec", "OnBootSec", "OnStartupSec", "OnUnitActiveSec", "OnUnitInactiveSec", "OnCalendar",
+		"AccuracySec", "RandomizedDelaySec", "FixedRandomDelay", "OnClockChange", "OnTimezoneChange", "Unit",
+		"Persistent", "WakeSystem", "RemainAfterElapse",
+	}
+	pathDirectives = Strings{
+		"PathExists", "PathExistsGlob", "PathChanged", "PathModified", "DirectoryNotEmpty", "Unit",
+		"MakeDirectory", "DirectoryMode", "TriggerLimitIntervalSec", "TriggerLimitBurst",
+	}
+	mountDirectives = Strings{
+		"What", "Where", "Type", "Options", "SloppyOptions", "LazyUnmount", "ReadWriteOnly", "ForceUnmount",
+		"DirectoryMode", "TimeoutSec",
+	}
+	automountDirectives = Strings{"Where", "ExtraOptions", "DirectoryMode", "TimeoutIdleSec"}
+	swapDirectives      = Strings{"What", "Priority", "Options", "TimeoutSec"}
+	scopeDirectives     = Strings{"RuntimeMaxSec", "RuntimeRandomizedExtraSec", "OOMPolicy"}
+
+	// execDirectives configure the execution environment of processes
+	execDirectives = Strings{
+		"WorkingDirectory", "RootDirectory", "RootImage", "RootImageOptions", "RootHash", "RootHashSignature",
+		"RootVerity", "MountAPIVFS", "ProtectProc", "ProcSubset", "BindPaths", "BindReadOnlyPaths",
+		"MountImages", "ExtensionImages", "ExtensionDirectories", "User", "Group", "DynamicUser",
+		"SupplementaryGroups", "PAMName", "CapabilityBoundingSet", "AmbientCapabilities", "NoNewPrivileges",
+		"SecureBits", "SELinuxContext", "AppArmorProfile", "SmackProcessLabel", "LimitCPU", "LimitFSIZE",
+		"LimitDATA", "LimitSTACK", "LimitCORE", "LimitRSS", "LimitNOFILE", "LimitAS", "LimitNPROC",
+		"LimitMEMLOCK", "LimitLOCKS", "LimitSIGPENDING", "LimitMSGQUEUE", "LimitNICE", "LimitRTPRIO",
+		"LimitRTTIME", "UMask", "CoredumpFilter", "KeyringMode", "OOMScoreAdjust", "TimerSlackNSec",
+		"Personality", "IgnoreSIGPIPE", "Nice", "CPUSchedulingPolicy", "CPUSchedulingPriority",
+		"CPUSchedulingResetOnFork", "CPUAffinity", "NUMAPolicy", "NUMAMask", "IOSchedulingClass",
+		"IOSchedulingPriority", "ProtectSystem", "ProtectHome", "RuntimeDirectory", "StateDirectory",
+		"CacheDirectory", "LogsDirectory", "ConfigurationDirectory", "RuntimeDirectoryMode",
+		"StateDirectoryMode", "CacheDirectoryMode", "LogsDirectoryMode", "ConfigurationDirectoryMode",
+		"RuntimeDirectoryPreserve", "TimeoutCleanSec", "ReadWritePaths", "ReadOnlyPaths", "InaccessiblePaths",
+		"ExecPaths", "NoExecPaths", "TemporaryFileSystem", "PrivateTmp", "PrivateDevices", "PrivateNetwork",
+		"NetworkNamespacePath", "PrivateIPC", "IPCNamespacePath", "PrivateUsers", "ProtectHostname",
+		"ProtectClock", "ProtectKernelTunables", "ProtectKernelModules", "ProtectKernelLogs",
+		"ProtectControlGroups", "RestrictAddressFamilies", "RestrictFileSystems", "RestrictNamespaces",
+		"LockPersonality", "MemoryDenyWriteExecute", "RestrictRealtime", "RestrictSUIDSGID", "RemoveIPC",
+		"PrivateMounts", "MountFlags", "SystemCallFilter", "SystemCallErrorNumber", "SystemCallArchitectures",
+		"SystemCallLog", "Environment", "EnvironmentFile", "PassEnvironment", "UnsetEnvironment",
+		"StandardInput", "StandardOutput", "StandardError", "StandardInputText", "StandardInputData",
+		"LogLevelMax", "LogExtraFields", "LogRateLimitIntervalSec", "LogRateLimitBurst", "LogFilterPatterns",
+		"LogNamespace", "SyslogIdentifier", "SyslogFacility", "SyslogLevel", "SyslogLevelPrefix", "TTYPath",
+		"TTYReset", "TTYVHangup", "TTYRows", "TTYColumns", "TTYVTDisallocate", "LoadCredential",
+		"LoadCredentialEncrypted", "ImportCredential", "SetCredential", "SetCredentialEncrypted",
+		"UtmpIdentifier", "UtmpMode",
+	}
+	// killDirectives configure how processes get stopped
+	killDirectives = Strings{
+		"KillMode", "KillSignal", "RestartKillSignal", "SendSIGHUP", "SendSIGKILL", "FinalKillSignal",
+		"WatchdogSignal",
+	}
+	// resourceDirectives configure the cgroup of a unit
+	resourceDirectives = Strings{
+		"CPUAccounting", "CPUWeight", "StartupCPUWeight", "CPUQuota", "CPUQuotaPeriodSec", "AllowedCPUs",
+		"StartupAllowedCPUs", "AllowedMemoryNodes", "StartupAllowedMemoryNodes", "MemoryAccounting",
+		"MemoryMin", "MemoryLow", "MemoryHigh", "MemoryMax", "MemorySwapMax", "MemoryZSwapMax",
+		"TasksAccounting", "TasksMax", "IOAccounting", "IOWeight", "StartupIOWeight", "IODeviceWeight",
+		"IOReadBandwidthMax", "IOWriteBandwidthMax", "IOReadIOPSMax", "IOWriteIOPSMax",
+		"IODeviceLatencyTargetSec", "IPAccounting", "IPAddressAllow", "IPAddressDeny", "IPIngressFilterPath",
+		"IPEgressFilterPath", "BPFProgram", "SocketBindAllow", "SocketBindDeny", "RestrictNetworkInterfaces",
+		"DeviceAllow", "DevicePolicy", "Slice", "Delegate", "DisableControllers", "ManagedOOMSwap",
+		"ManagedOOMMemoryPressure", "ManagedOOMMemoryPressureLimit", "ManagedOOMPreference", "CPUShares",
+		"StartupCPUShares", "MemoryLimit", "BlockIOAccounting", "BlockIOWeight", "StartupBlockIOWeight",
+		"BlockIODeviceWeight", "BlockIOReadBandwidth", "BlockIOWriteBandwidth",
+	}
+
+	// sectionDirectives lists the directives valid in each section
+	sectionDirectives = map[string][]Strings{
+		"Unit":      {unitDirectives},
+		"Install":   {installDirectives},
+		"Service":   {serviceDirectives, execDirectives, killDirectives, resourceDirectives},
+		"Socket":    {socketDirectives, execDirectives, killDirectives, resourceDirectives},
+		"Mount":     {mountDirectives, execDirectives, killDirectives, resourceDirectives},
+		"Automount": {automountDirectives},
+		"Swap":      {swapDirectives, execDirectives, killDirectives, resourceDirectives},
+		"Timer":     {timerDirectives},
+		"Path":      {pathDirectives},
+		"Slice":     {resourceDirectives},
+		"Scope":     {scopeDirectives, killDirectives, resourceDirectives},
+	}
+	// unitSections maps unit file suffixes to the type specific section
+	unitSections = map[string]string{
+		".service":   "Service",
+		".socket":    "Socket",
+		".mount":     "Mount",
+		".automount": "Automount",
+		".swap":      "Swap",
+		".timer":     "Timer",
+		".path":      "Path",
+		".slice":     "Slice",
+		".scope":     "Scope",
+	}
+
+	// timeSpanDirectives take a plain time span, unlike e.g.
+	// IODeviceLatencyTargetSec which is preceded by a device
+	timeSpanDirectives = Strings{
+		"AccuracySec", "CPUQuotaPeriodSec", "DeferAcceptSec", "JobRunningTimeoutSec", "JobTimeoutSec",
+		"KeepAliveIntervalSec", "KeepAliveTimeSec", "LogRateLimitIntervalSec", "OnActiveSec", "OnBootSec",
+		"OnStartupSec", "OnUnitActiveSec", "OnUnitInactiveSec", "PollLimitIntervalSec", "RandomizedDelaySec",
+		"RestartMaxDelaySec", "RestartSec", "RuntimeMaxSec", "RuntimeRandomizedExtraSec",
+		"StartLimitIntervalSec", "TimeoutAbortSec", "TimeoutCleanSec", "TimeoutIdleSec", "TimeoutSec",
+		"TimeoutStartSec", "TimeoutStopSec", "TriggerLimitIntervalSec", "WatchdogSec",
+	}
+)
+
+// isKnownDirective returns whether name is a valid directive in section.
+func isKnownDirective(section, name string) bool {
+	if section == "Unit" {
+		for _, prefix := range []string{"Condition", "Assert"} {
+			if strings.HasPrefix(name, prefix) && conditions.Contains(strings.TrimPrefix(name, prefix)) {
+				return true
+			}
+		}
+	}
+
+	for _, ds := range sectionDirectives[section] {
+		if ds.Contains(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suggestDirective returns the known directive of section closest to name,
+// or an empty string if none is similar enough.
+func suggestDirective(section, name string) string {
+	best := ""
+	bestDist := len(name)/3 + 1
+	for _, ds := range sectionDirectives[section] {
+		for _, d := range ds {
+			if dist := levenshtein(strings.ToLower(name), strings.ToLower(d)); dist < bestDist {
+				best = d
+				bestDist = dist
+			}
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min(vs ...int) int {
+	m := vs[0]
+	for _, v := range vs[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type managedDirectory struct {
+	Name  string
+	Base  string
+	Usage string
+}
+
+var (
+	// managedDirectories are the directories systemd creates for a service
+	// below their base directory, owned by the service's user
+	managedDirectories = []managedDirectory{
+		{"RuntimeDirectory", "/run", "Directories to create in /run, removed when the service stops"},
+		{"StateDirectory", "/var/lib", "Directories to create in /var/lib for persistent state"},
+		{"CacheDirectory", "/var/cache", "Directories to create in /var/cache for cached data"},
+		{"LogsDirectory", "/var/log", "Directories to create in /var/log for log files"},
+		{"ConfigurationDirectory", "/etc", "Directories to create in /etc for configuration"},
+	}
+
+	fileMode = regexp.MustCompile(`^0?[0-7]{3,4}$`)
+)
+
+// directoryField returns the CreateOptions field holding a managed directory
+// directive or its mode.
+func directoryField(name string) *string {
+	switch name {
+	case "RuntimeDirectory":
+		return &createOpts.RuntimeDirectory
+	case "RuntimeDirectoryMode":
+		return &createOpts.RuntimeDirectoryMode
+	case "StateDirectory":
+		return &createOpts.StateDirectory
+	case "StateDirectoryMode":
+		return &createOpts.StateDirectoryMode
+	case "CacheDirectory":
+		return &createOpts.CacheDirectory
+	case "CacheDirectoryMode":
+		return &createOpts.CacheDirectoryMode
+	case "LogsDirectory":
+		return &createOpts.LogsDirectory
+	case "LogsDirectoryMode":
+		return &createOpts.LogsDirectoryMode
+	case "ConfigurationDirectory":
+		return &createOpts.ConfigurationDirectory
+	case "ConfigurationDirectoryMode":
+		return &createOpts.ConfigurationDirectoryMode
+	default:
+		return nil
+	}
+}
+
+func validateDirectories() error {
+	for _, d := range managedDirectories {
+		for _, dir := range strings.Fields(*directoryField(d.Name)) {
+			if filepath.IsAbs(dir) || filepath.Clean(dir) != dir || strings.HasPrefix(dir, "..") {
+				return fmt.Errorf("Invalid value for %s: %s (expected a path relative to %s)", d.Name, dir, d.Base)
+			}
+		}
+
+		mode := *directoryField(d.Name + "Mode")
+		if len(mode) > 0 && !fileMode.MatchString(mode) {
+			return fmt.Errorf("Invalid value for %sMode: %s (expected an octal mode like 0755)", d.Name, mode)
+		}
+	}
+
+	return nil
+}
+
+func directoryOptions() []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, d := range managedDirectories {
+		u = append(u,
+			&unit.UnitOption{"Service", d.Name, *directoryField(d.Name)},
+			&unit.UnitOption{"Service", d.Name + "Mode", *directoryField(d.Name + "Mode")})
+	}
+
+	return stripEmptyOptions(u)
+}
+
+func init() {
+	for _, d := range managedDirectories {
+		createCmd.PersistentFlags().StringVar(directoryField(d.Name), strings.ToLower(d.Name), "", d.Usage+" (space separated)")
+		createCmd.PersistentFlags().StringVar(directoryField(d.Name+"Mode"), strings.ToLower(d.Name+"Mode"), "", fmt.Sprintf("Access mode of the directories in %s (default 0755)", d.Base))
+	}
+}
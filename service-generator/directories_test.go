@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestValidateDirectories(t *testing.T) {
+	tests := []struct {
+		dir   string
+		mode  string
+		valid bool
+	}{
+		{"app", "", true},
+		{"app app/cache", "0750", true},
+		{"app", "750", true},
+		{"app", "1777", true},
+		{"app", "02770", true},
+		{"/var/lib/app", "", false},
+		{"../app", "", false},
+		{"app/../other", "", false},
+		{"app", "0758", false},
+		{"app", "75", false},
+		{"app", "u+rwx", false},
+	}
+
+	for _, test := range tests {
+		createOpts = CreateOptions{StateDirectory: test.dir, StateDirectoryMode: test.mode}
+		err := validateDirectories()
+		if test.valid && err != nil {
+			t.Errorf("validateDirectories(%q, %q): %s", test.dir, test.mode, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validateDirectories(%q, %q): expected an error", test.dir, test.mode)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// extraOptions holds all options of a loaded Unit file which aren't
+	// represented in CreateOptions, so they survive being written back
+	extraOptions []*unit.UnitOption
+
+	editCmd = &cobra.Command{
+		Use:   "edit <unit-file>",
+		Short: "edits an existing Unit file",
+		Long:  `The edit command loads an existing systemd Unit file and lets you modify it`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ts, err := targets()
+			if err != nil {
+				return fmt.Errorf("Can't find systemd targets: %s", err)
+			}
+
+			filename := args[0]
+			if err := loadUnit(filename); err != nil {
+				return err
+			}
+			if err := validateTypes(); err != nil {
+				return err
+			}
+
+			return runForm("Edit "+filepath.Base(filename), "Save", ts, servicePreview, validated(func() error {
+				base := strings.TrimSuffix(strings.TrimSuffix(filename, ".service"), "@")
+				if err := importEnvFile(base); err != nil {
+					return err
+				}
+				if err := credentialEnvironment(base); err != nil {
+					return err
+				}
+				if err := writeUnit(filename, serviceOptions()); err != nil {
+					return err
+				}
+				return writeCompanionUnits(base)
+			}))
+		},
+	}
+)
+
+// knownOptions maps the unit options the generator understands to the
+// CreateOptions fields holding their values.
+func knownOptions() map[string]*string {
+	opts := map[string]*string{
+		"Unit.Description": &createOpts.Description,
+		"Unit.After":       &createOpts.After,
+
+		"Service.Type":             &createOpts.Type,
+		"Service.PIDFile":          &createOpts.PIDFile,
+		"Service.WorkingDirectory": &createOpts.WorkingDirectory,
+		"Service.RootDirectory":    &createOpts.RootDirectory,
+
+		"Service.ExecStart":  &createOpts.Exec,
+		"Service.ExecReload": &createOpts.ExecReload,
+
+		"Service.User":            &createOpts.User,
+		"Service.Group":           &createOpts.Group,
+		"Service.Restart":         &createOpts.Restart,
+		"Service.RestartSec":      &createOpts.RestartSec,
+		"Service.TimeoutStartSec": &createOpts.TimeoutStartSec,
+		"Service.TimeoutStopSec":  &createOpts.TimeoutStopSec,
+
+		"Service.Slice":       &createOpts.Slice,
+		"Service.MemoryMax":   &createOpts.MemoryMax,
+		"Service.MemoryHigh":  &createOpts.MemoryHigh,
+		"Service.CPUQuota":    &createOpts.CPUQuota,
+		"Service.CPUWeight":   &createOpts.CPUWeight,
+		"Service.IOWeight":    &createOpts.IOWeight,
+		"Service.TasksMax":    &createOpts.TasksMax,
+		"Service.LimitNOFILE": &createOpts.LimitNOFILE,
+
+		"Service.StandardOutput":          &createOpts.Logging.StandardOutput,
+		"Service.StandardError":           &createOpts.Logging.StandardError,
+		"Service.SyslogIdentifier":        &createOpts.Logging.SyslogIdentifier,
+		"Service.SyslogFacility":          &createOpts.Logging.SyslogFacility,
+		"Service.LogLevelMax":             &createOpts.Logging.LogLevelMax,
+		"Service.LogRateLimitIntervalSec": &createOpts.Logging.LogRateLimitIntervalSec,
+		"Service.LogRateLimitBurst":       &createOpts.Logging.LogRateLimitBurst,
+
+		"Install.WantedBy": &createOpts.WantedBy,
+	}
+	for _, d := range dependencies {
+		opts[d.Section+"."+d.Name] = dependencyField(d.Name)
+	}
+	for _, d := range managedDirectories {
+		opts["Service."+d.Name] = directoryField(d.Name)
+		opts["Service."+d.Name+"Mode"] = directoryField(d.Name + "Mode")
+	}
+
+	return opts
+}
+
+func readUnit(filename string) ([]*unit.UnitOption, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	opts, err := unit.Deserialize(f)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse Unit file: %s", err)
+	}
+
+	return opts, nil
+}
+
+// loadUnit replaces createOpts with the contents of an existing Unit file.
+func loadUnit(filename string) error {
+	opts, err := readUnit(filename)
+	if err != nil {
+		return err
+	}
+
+	return loadOptions(opts, strings.HasSuffix(filename, "@.service"))
+}
+
+// loadOptions replaces createOpts with the given unit options. Options which
+// appear more than once keep their first value in createOpts, all further
+// occurrences are kept in extraOptions.
+func loadOptions(opts []*unit.UnitOption, template bool) error {
+	createOpts = CreateOptions{
+		Template:  template,
+		Hardening: map[string]string{},
+	}
+	extraOptions = nil
+	fields := knownOptions()
+	for _, opt := range opts {
+		if opt.Section == "Service" {
+			switch {
+			case opt.Name == "Environment":
+				kvs, err := parseEnvironment(opt.Value)
+				if err != nil {
+					return err
+				}
+				createOpts.Environment = append(createOpts.Environment, kvs...)
+				continue
+			case opt.Name == "DynamicUser":
+				createOpts.DynamicUser = isTrue(opt.Value)
+				continue
+			case opt.Name == "LogExtraFields":
+				kvs, err := parseEnvironment(opt.Value)
+				if err != nil {
+					return err
+				}
+				createOpts.Logging.LogExtraFields = append(createOpts.Logging.LogExtraFields, kvs...)
+				continue
+			case opt.Name == "EnvironmentFile":
+				createOpts.EnvironmentFiles = append(createOpts.EnvironmentFiles, opt.Value)
+				continue
+			case opt.Name == "LoadCredential":
+				createOpts.LoadCredentials = append(createOpts.LoadCredentials, opt.Value)
+				continue
+			case opt.Name == "SetCredential":
+				createOpts.SetCredentials = append(createOpts.SetCredentials, credentialUnescaper.Replace(opt.Value))
+				continue
+			case commandHooks.Contains(opt.Name):
+				cmds := hookCommands(opt.Name)
+				if len(opt.Value) == 0 {
+					// an empty assignment resets the list
+					*cmds = nil
+				} else {
+					*cmds = append(*cmds, opt.Value)
+				}
+				continue
+			case isHardeningDirective(opt.Name) && (len(createOpts.Hardening[opt.Name]) == 0 || !listOptions.Contains(opt.Name)):
+				createOpts.Hardening[opt.Name] = opt.Value
+				continue
+			}
+		}
+
+		// systemd uses the last assignment of single value options, further
+		// assignments of list options are kept as they are
+		v, ok := fields[opt.Section+"."+opt.Name]
+		if !ok || (len(*v) > 0 && listOptions.Contains(opt.Name)) {
+			extraOptions = append(extraOptions, opt)
+			continue
+		}
+		*v = opt.Value
+	}
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(editCmd)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+func TestLoadOptions(t *testing.T) {
+	opts, err := unit.Deserialize(strings.NewReader(`[Unit]
+Description=Test
+After=network.target
+After=postgresql.service
+
+[Service]
+ExecStart=/usr/bin/app
+User=nobody
+ProtectSystem=full
+User=www
+ProtectSystem=strict
+Nice=5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loadOptions(opts, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// systemd uses the last assignment
+	if createOpts.User != "www" {
+		t.Errorf("Expected User=www, got %s", createOpts.User)
+	}
+	if createOpts.Hardening["ProtectSystem"] != "strict" {
+		t.Errorf("Expected ProtectSystem=strict, got %s", createOpts.Hardening["ProtectSystem"])
+	}
+	if createOpts.After != "network.target" {
+		t.Errorf("Expected After=network.target, got %s", createOpts.After)
+	}
+
+	var extras []string
+	for _, o := range extraOptions {
+		extras = append(extras, o.Name+"="+o.Value)
+	}
+	if strings.Join(extras, " ") != "After=postgresql.service Nice=5" {
+		t.Errorf("Unexpected extra options: %v", extras)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+var envKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// splitEnv splits a KEY=VALUE assignment into its key and value.
+func splitEnv(kv string) (string, string, error) {
+	i := strings.Index(kv, "=")
+	if i < 0 {
+		return "", "", fmt.Errorf("Environment variable needs to be of the form KEY=VALUE: %s", kv)
+	}
+	if !envKey.MatchString(kv[:i]) {
+		return "", "", fmt.Errorf("Invalid environment variable name: %s", kv[:i])
+	}
+
+	return kv[:i], kv[i+1:], nil
+}
+
+// quoteEnv quotes a KEY=VALUE assignment for use in an Environment= line.
+func quoteEnv(kv string) string {
+	return quoteWord(strings.Replace(kv, "%", "%%", -1))
+}
+
+func validateEnvironment() error {
+	for _, kv := range createOpts.Environment {
+		if _, _, err := splitEnv(kv); err != nil {
+			return err
+		}
+	}
+	for _, f := range createOpts.EnvironmentFiles {
+		// a leading '-' makes the file optional
+		if !filepath.IsAbs(strings.TrimPrefix(f, "-")) {
+			return fmt.Errorf("EnvironmentFile needs an absolute path: %s", f)
+		}
+	}
+	if len(createOpts.EnvImport) > 0 {
+		if _, err := readEnvFile(createOpts.EnvImport); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func environmentOptions() []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, f := range createOpts.EnvironmentFiles {
+		u = append(u, &unit.UnitOption{"Service", "EnvironmentFile", f})
+	}
+	for _, kv := range createOpts.Environment {
+		u = append(u, &unit.UnitOption{"Service", "Environment", quoteEnv(kv)})
+	}
+
+	return u
+}
+
+// parseEnvironment returns the assignments of an Environment= line.
+func parseEnvironment(value string) ([]string, error) {
+	kvs, err := splitQuoted(value)
+	if err != nil {
+		return nil, err
+	}
+	for i, kv := range kvs {
+		kvs[i] = strings.Replace(kv, "%%", "%", -1)
+	}
+
+	return kvs, nil
+}
+
+// readEnvFile reads the variables of a dotenv style file. Comments, empty
+// lines and "export" prefixes are ignored.
+func readEnvFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open environment file: %s", err)
+	}
+	defer f.Close()
+
+	var kvs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		k, v, err := splitEnv(line)
+		if err != nil {
+			return nil, err
+		}
+		words, err := splitQuoted(v)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, k+"="+strings.Join(words, " "))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read environment file: %s", err)
+	}
+
+	return kvs, nil
+}
+
+// importEnvFile copies the variables of the file given with --env-import to
+// an EnvironmentFile next to the unit and references it from the service.
+func importEnvFile(base string) error {
+	if len(createOpts.EnvImport) == 0 {
+		return nil
+	}
+
+	kvs, err := readEnvFile(createOpts.EnvImport)
+	if err != nil {
+		return err
+	}
+	if kvs, err = moveSecrets(base, kvs); err != nil {
+		return err
+	}
+
+	filename, err := filepath.Abs(base + ".env")
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	for _, kv := range kvs {
+		k, v, _ := splitEnv(kv)
+		fmt.Fprintf(&b, "%s=%s\n", k, quoteWord(v))
+	}
+
+	// the variables are often credentials, keep them private
+	changed, err := writeFile(filename, b.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("Generated environment file: %s\n", filename)
+	} else {
+		fmt.Printf("Environment file unchanged: %s\n", filename)
+	}
+	if !Strings(createOpts.EnvironmentFiles).Contains(filename) {
+		createOpts.EnvironmentFiles = append(createOpts.EnvironmentFiles, filename)
+	}
+	return nil
+}
+
+func init() {
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.Environment, "env", nil, "Environment variable to set for the service (KEY=VALUE, repeatable)")
+	createCmd.PersistentFlags().StringArrayVar(&createOpts.EnvironmentFiles, "env-file", nil, "Absolute path of a file to read environment variables from (repeatable)")
+	createCmd.PersistentFlags().StringVar(&createOpts.EnvImport, "env-import", "", "Local .env file to copy into an EnvironmentFile next to the unit")
+}
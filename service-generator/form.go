@@ -0,0 +1,707 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// sections are the tabs of the form, in the order they're shown.
+var sections = []string{"Basics", "Execution", "Commands", "Environment", "Dependencies", "Restart",
+	"Sandboxing", "Directories", "Resources", "Logging", "Timer", "Socket"}
+
+// runForm shows the form editing createOpts next to a preview of what
+// submitting it would write. Submitting is responsible for validating the
+// options.
+func runForm(title, button string, ts Targets, preview func() string, submit func() error) error {
+	app := tview.NewApplication()
+	pages := tview.NewPages()
+
+	var apperr error
+	buttons := func(form *tview.Form) {
+		form.
+			AddButton(button, func() {
+				app.Stop()
+				apperr = submit()
+			}).
+			AddButton("Cancel", func() {
+				app.Stop()
+			})
+	}
+
+	pages.
+		AddPage("Basics", basicsForm(buttons), true, true).
+		AddPage("Execution", executionForm(buttons), true, false).
+		AddPage("Commands", commandsForm(app, buttons), true, false).
+		AddPage("Environment", environmentForm(app, buttons), true, false).
+		AddPage("Dependencies", dependenciesForm(app, ts, buttons), true, false).
+		AddPage("Restart", restartForm(buttons), true, false).
+		AddPage("Sandboxing", hardeningForm(buttons), true, false).
+		AddPage("Directories", directoriesForm(buttons), true, false).
+		AddPage("Resources", resourcesForm(buttons), true, false).
+		AddPage("Logging", loggingForm(buttons), true, false).
+		AddPage("Timer", timerForm(buttons), true, false).
+		AddPage("Socket", socketForm(buttons), true, false)
+
+	tabs := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true)
+	for i, s := range sections {
+		fmt.Fprintf(tabs, `["%d"] %s [""] `, i, s)
+	}
+	current := 0
+	switchTo := func(i int) {
+		current = (i + len(sections)) % len(sections)
+		pages.SwitchToPage(sections[current])
+		tabs.Highlight(strconv.Itoa(current))
+	}
+	switchTo(0)
+	tabs.SetBorder(true).SetTitle(title + " (Ctrl-N/Ctrl-P to switch sections)").SetTitleAlign(tview.AlignCenter)
+
+	// the preview gets re-rendered before every redraw, so it follows each
+	// change made in the form
+	previewView := tview.NewTextView()
+	previewView.SetBorder(true).SetTitle("Preview")
+	app.SetBeforeDrawFunc(func(tcell.Screen) bool {
+		previewView.SetText(preview())
+		return false
+	})
+
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyCtrlN:
+			switchTo(current + 1)
+			return nil
+		case tcell.KeyCtrlP:
+			switchTo(current - 1)
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(tabs, 4, 0, false).
+			AddItem(pages, 0, 1, true), 0, 3, true).
+		AddItem(previewView, 0, 2, false)
+	if err := app.SetRoot(layout, true).Run(); err != nil {
+		return err
+	}
+	return apperr
+}
+
+// servicePreview returns the Unit files generated for createOpts.
+func servicePreview() string {
+	// show the options as they'd get written, without changing the ones
+	// edited in the form
+	edited := createOpts
+	defer func() {
+		createOpts = edited
+	}()
+	normalizeOptions()
+
+	name := serviceName()
+	service := name + ".service"
+	if isTemplate() {
+		service = name + "@.service"
+	}
+
+	s := fmt.Sprintf("# %s\n%s", service, serializeOptions(serviceOptions()))
+	if createOpts.Timer.Enabled {
+		s += fmt.Sprintf("\n# %s.timer\n%s", name, serializeOptions(timerOptions()))
+	}
+	if createOpts.Socket.Enabled {
+		s += fmt.Sprintf("\n# %s.socket\n%s", name, serializeOptions(socketOptions()))
+	}
+	return s
+}
+
+func serializeOptions(opts []*unit.UnitOption) string {
+	b, err := ioutil.ReadAll(unit.Serialize(opts))
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func basicsForm(buttons func(*tview.Form)) *tview.Form {
+	i := &createOpts.Install
+	descriptionField := tview.NewInputField().
+		SetLabel("Description:").
+		SetText(createOpts.Description).
+		SetFieldWidth(40).
+		SetAcceptanceFunc(nil).
+		SetChangedFunc(func(s string) {
+			createOpts.Description = s
+		})
+
+	instances := ""
+	if createOpts.Instances > 0 {
+		instances = strconv.Itoa(createOpts.Instances)
+	}
+
+	form := tview.NewForm().
+		AddInputField("Exec on start:", createOpts.Exec, 40, nil, func(s string) {
+			createOpts.Exec = s
+			descriptionField.SetText(fmt.Sprintf("%s service", serviceName()))
+		}).
+		AddFormItem(descriptionField).
+		AddInputField("Name:", createOpts.Name, 30, nil, func(s string) {
+			createOpts.Name = s
+		}).
+		AddDropDown("Type:", types, types.IndexOf(createOpts.Type), func(s string, i int) {
+			createOpts.Type = s
+		}).
+		AddInputField("PID file:", createOpts.PIDFile, 40, nil, func(s string) {
+			createOpts.PIDFile = s
+		}).
+		AddCheckbox("Template:", createOpts.Template, func(checked bool) {
+			createOpts.Template = checked
+		}).
+		AddInputField("Instances:", instances, 5, tview.InputFieldInteger, func(s string) {
+			createOpts.Instances, _ = strconv.Atoi(s)
+		}).
+		AddCheckbox("Install:", i.Install, func(checked bool) {
+			i.Install = checked
+		}).
+		AddInputField("Unit directory:", i.UnitDir, 40, nil, func(s string) {
+			i.UnitDir = s
+		}).
+		AddCheckbox("Enable:", i.Enable, func(checked bool) {
+			i.Enable = checked
+		}).
+		AddCheckbox("Start:", i.Start, func(checked bool) {
+			i.Start = checked
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Basics").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func executionForm(buttons func(*tview.Form)) *tview.Form {
+	form := tview.NewForm().
+		AddInputField("Exec on reload:", createOpts.ExecReload, 40, nil, func(s string) {
+			createOpts.ExecReload = s
+		}).
+		AddInputField("Working directory:", createOpts.WorkingDirectory, 40, nil, func(s string) {
+			createOpts.WorkingDirectory = s
+		}).
+		AddInputField("Root directory:", createOpts.RootDirectory, 40, nil, func(s string) {
+			createOpts.RootDirectory = s
+		}).
+		AddInputField("User:", createOpts.User, 20, nil, func(s string) {
+			createOpts.User = s
+		}).
+		AddInputField("Group:", createOpts.Group, 20, nil, func(s string) {
+			createOpts.Group = s
+		}).
+		AddCheckbox("Dynamic user:", createOpts.DynamicUser, func(checked bool) {
+			createOpts.DynamicUser = checked
+		}).
+		AddCheckbox("Create user with sysusers.d:", createOpts.SysUsers, func(checked bool) {
+			createOpts.SysUsers = checked
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Execution").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func restartForm(buttons func(*tview.Form)) *tview.Form {
+	form := tview.NewForm().
+		AddDropDown("Restarts on:", restarts, restarts.IndexOf(createOpts.Restart), func(s string, i int) {
+			createOpts.Restart = s
+		}).
+		AddInputField("Restart after:", createOpts.RestartSec, 10, nil, func(s string) {
+			createOpts.RestartSec = s
+		}).
+		AddInputField("Start timeout:", createOpts.TimeoutStartSec, 10, nil, func(s string) {
+			createOpts.TimeoutStartSec = s
+		}).
+		AddInputField("Stop timeout:", createOpts.TimeoutStopSec, 10, nil, func(s string) {
+			createOpts.TimeoutStopSec = s
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Restart").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func commandsForm(app *tview.Application, buttons func(*tview.Form)) tview.Primitive {
+	type entry struct {
+		hook  string
+		index int
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	hook := commandHooks[0]
+	hookField := tview.NewDropDown().
+		SetLabel("Run as:").
+		SetOptions(commandHooks, func(s string, i int) {
+			hook = s
+		}).
+		SetCurrentOption(0)
+	commandField := tview.NewInputField().SetLabel("Command:").SetFieldWidth(40)
+
+	// entries remembers which hook and position each list item belongs to,
+	// edited commands get put back at their position
+	var entries []entry
+	edited := entry{index: -1}
+	refresh := func() {
+		list.Clear()
+		entries = nil
+		for _, h := range commandHooks {
+			for i, c := range *hookCommands(h) {
+				list.AddItem(h+": "+c, "", 0, nil)
+				entries = append(entries, entry{h, i})
+			}
+		}
+	}
+	refresh()
+
+	form := tview.NewForm().
+		AddFormItem(hookField).
+		AddFormItem(commandField).
+		AddButton("Add", func() {
+			if len(strings.TrimSpace(commandField.GetText())) == 0 {
+				return
+			}
+			cmds := hookCommands(hook)
+			i := len(*cmds)
+			if edited.hook == hook && edited.index >= 0 && edited.index < i {
+				i = edited.index
+			}
+			*cmds = append((*cmds)[:i], append([]string{commandField.GetText()}, (*cmds)[i:]...)...)
+			edited = entry{index: -1}
+			commandField.SetText("")
+			refresh()
+		})
+
+	// selecting a command moves it back into the editor
+	list.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		edited = entries[i]
+		cmds := hookCommands(edited.hook)
+		hookField.SetCurrentOption(commandHooks.IndexOf(edited.hook))
+		commandField.SetText((*cmds)[edited.index])
+		*cmds = append((*cmds)[:edited.index], (*cmds)[edited.index+1:]...)
+		refresh()
+		app.SetFocus(form)
+	})
+	list.SetDoneFunc(func() {
+		app.SetFocus(form)
+	})
+	form.SetCancelFunc(func() {
+		app.SetFocus(list)
+	})
+
+	list.SetBorder(true).SetTitle("Commands (Esc to select, Enter to edit)")
+	buttons(form)
+	form.SetBorder(true).SetTitle("Commands").SetTitleAlign(tview.AlignCenter)
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, false).
+		AddItem(form, 0, 1, true)
+}
+
+func dependenciesForm(app *tview.Application, ts Targets, buttons func(*tview.Form)) tview.Primitive {
+	// without a connection to systemd the units can still be typed in
+	us, _ := units()
+	list := tview.NewList().ShowSecondaryText(false)
+	form := tview.NewForm()
+
+	var names Strings
+	fields := map[string]*tview.InputField{}
+	for _, d := range dependencies {
+		name := d.Name
+		names = append(names, name)
+		fields[name] = tview.NewInputField().
+			SetLabel(name + ":").
+			SetText(*dependencyField(name)).
+			SetFieldWidth(40).
+			SetChangedFunc(func(s string) {
+				*dependencyField(name) = s
+			})
+	}
+
+	// the list marks the units picked for the current directive
+	current := names[0]
+	refresh := func() {
+		picked := Strings(strings.Fields(*dependencyField(current)))
+		for i, u := range us {
+			mark := "[ ] "
+			if picked.Contains(u) {
+				mark = "[x] "
+			}
+			list.SetItemText(i, mark+u, "")
+		}
+		list.SetTitle(fmt.Sprintf("Units for %s (Esc to select, Enter to toggle)", current))
+	}
+	for _, u := range us {
+		list.AddItem(u, "", 0, nil)
+	}
+
+	form.
+		AddDropDown("Start after target:", ts.Strings(), Strings(ts.Strings()).IndexOf(createOpts.After), func(s string, i int) {
+			createOpts.After = s
+		}).
+		AddDropDown("Wanted by target:", ts.Strings(), Strings(ts.Strings()).IndexOf(createOpts.WantedBy), func(s string, i int) {
+			createOpts.WantedBy = s
+		}).
+		AddDropDown("Pick units for:", names, 0, func(s string, i int) {
+			current = s
+			refresh()
+		})
+	for _, name := range names {
+		form.AddFormItem(fields[name])
+	}
+
+	list.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		picked := Strings(strings.Fields(*dependencyField(current)))
+		if j := picked.IndexOf(us[i]); j >= 0 {
+			picked = append(picked[:j], picked[j+1:]...)
+		} else {
+			picked = append(picked, us[i])
+		}
+		fields[current].SetText(strings.Join(picked, " "))
+		refresh()
+	})
+	list.SetDoneFunc(func() {
+		app.SetFocus(form)
+	})
+	form.SetCancelFunc(func() {
+		app.SetFocus(list)
+	})
+	refresh()
+
+	list.SetBorder(true)
+	buttons(form)
+	form.SetBorder(true).SetTitle("Dependencies").SetTitleAlign(tview.AlignCenter)
+	return tview.NewFlex().
+		AddItem(form, 0, 1, true).
+		AddItem(list, 0, 1, false)
+}
+
+func timerForm(buttons func(*tview.Form)) *tview.Form {
+	t := &createOpts.Timer
+	form := tview.NewForm().
+		AddCheckbox("Start periodically:", t.Enabled, func(checked bool) {
+			t.Enabled = checked
+		}).
+		AddInputField("On calendar:", t.OnCalendar, 40, nil, func(s string) {
+			t.OnCalendar = s
+		}).
+		AddInputField("After boot:", t.OnBootSec, 20, nil, func(s string) {
+			t.OnBootSec = s
+		}).
+		AddInputField("After last run:", t.OnUnitActiveSec, 20, nil, func(s string) {
+			t.OnUnitActiveSec = s
+		}).
+		AddCheckbox("Catch up missed runs:", t.Persistent, func(checked bool) {
+			t.Persistent = checked
+		}).
+		AddInputField("Randomized delay:", t.RandomizedDelaySec, 20, nil, func(s string) {
+			t.RandomizedDelaySec = s
+		}).
+		AddInputField("Accuracy:", t.AccuracySec, 20, nil, func(s string) {
+			t.AccuracySec = s
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Timer").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func socketForm(buttons func(*tview.Form)) *tview.Form {
+	so := &createOpts.Socket
+	form := tview.NewForm().
+		AddCheckbox("Start on demand:", so.Enabled, func(checked bool) {
+			so.Enabled = checked
+		}).
+		AddInputField("Listen on stream:", so.ListenStream, 40, nil, func(s string) {
+			so.ListenStream = s
+		}).
+		AddInputField("Listen on datagram:", so.ListenDatagram, 40, nil, func(s string) {
+			so.ListenDatagram = s
+		}).
+		AddInputField("Listen on FIFO:", so.ListenFIFO, 40, nil, func(s string) {
+			so.ListenFIFO = s
+		}).
+		AddCheckbox("One instance per connection:", so.Accept, func(checked bool) {
+			so.Accept = checked
+		}).
+		AddInputField("Socket user:", so.SocketUser, 20, nil, func(s string) {
+			so.SocketUser = s
+		}).
+		AddInputField("Socket mode:", so.SocketMode, 6, nil, func(s string) {
+			so.SocketMode = s
+		}).
+		AddDropDown("Bind IPv6 only:", bindIPv6Onlys, bindIPv6Onlys.IndexOf(so.BindIPv6Only), func(s string, i int) {
+			so.BindIPv6Only = s
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Socket").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func hardeningForm(buttons func(*tview.Form)) *tview.Form {
+	// remember the directive fields, so picking a profile can update them
+	setters := map[string]func(string){}
+
+	profile := tview.NewDropDown().
+		SetLabel("Profile:").
+		SetOptions(hardeningProfiles, func(s string, i int) {
+			if s == createOpts.HardeningProfile {
+				return
+			}
+			applyHardeningProfile(s)
+			hardening := createOpts.Hardening
+			for name, set := range setters {
+				set(hardening[name])
+			}
+		}).
+		SetCurrentOption(hardeningProfiles.IndexOf(createOpts.HardeningProfile))
+	form := tview.NewForm().
+		AddFormItem(profile)
+
+	for _, d := range hardeningDirectives {
+		name := d.Name
+		if d.List {
+			field := tview.NewInputField().
+				SetLabel(name + ":").
+				SetText(createOpts.Hardening[name]).
+				SetFieldWidth(30).
+				SetChangedFunc(func(s string) {
+					createOpts.Hardening[name] = s
+				})
+			setters[name] = func(s string) { field.SetText(s) }
+			form.AddFormItem(field)
+			continue
+		}
+
+		values := append(Strings{"", "true", "false"}, d.Values...)
+		if v := createOpts.Hardening[name]; !values.Contains(v) {
+			// keep values loaded from existing Unit files selectable
+			values = append(values, v)
+		}
+		dropdown := tview.NewDropDown().
+			SetLabel(name+":").
+			SetOptions(values, func(s string, i int) {
+				createOpts.Hardening[name] = s
+			}).
+			SetCurrentOption(values.IndexOf(createOpts.Hardening[name]))
+		setters[name] = func(s string) {
+			dropdown.SetCurrentOption(values.IndexOf(s))
+		}
+		form.AddFormItem(dropdown)
+	}
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Sandboxing").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func environmentForm(app *tview.Application, buttons func(*tview.Form)) tview.Primitive {
+	list := tview.NewList().ShowSecondaryText(false)
+	keyField := tview.NewInputField().SetLabel("Key:").SetFieldWidth(30)
+	valueField := tview.NewInputField().SetLabel("Value:").SetFieldWidth(40)
+
+	refresh := func() {
+		list.Clear()
+		for _, kv := range createOpts.Environment {
+			list.AddItem(kv, "", 0, nil)
+		}
+		for _, c := range createOpts.SetCredentials {
+			name, _, _ := splitCredential("SetCredential", c)
+			list.AddItem(fmt.Sprintf("%s (credential)", name), "", 0, nil)
+		}
+	}
+	refresh()
+
+	form := tview.NewForm().
+		AddFormItem(keyField).
+		AddFormItem(valueField).
+		AddInputField("Environment files:", strings.Join(createOpts.EnvironmentFiles, " "), 40, nil, func(s string) {
+			createOpts.EnvironmentFiles = strings.Fields(s)
+		}).
+		AddInputField("Import .env file:", createOpts.EnvImport, 40, nil, func(s string) {
+			createOpts.EnvImport = s
+		}).
+		AddInputField("Credential files:", strings.Join(createOpts.LoadCredentials, " "), 40, nil, func(s string) {
+			createOpts.LoadCredentials = strings.Fields(s)
+		}).
+		AddButton("Add", func() {
+			if len(keyField.GetText()) == 0 {
+				return
+			}
+			// secrets are handled as configured with --secret-env once
+			// the unit gets written
+			createOpts.Environment = append(createOpts.Environment, keyField.GetText()+"="+valueField.GetText())
+			keyField.SetText("")
+			valueField.SetText("")
+			refresh()
+		})
+
+	// selecting a variable moves it back into the editor
+	list.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		if i < len(createOpts.Environment) {
+			parts := append(strings.SplitN(createOpts.Environment[i], "=", 2), "")
+			keyField.SetText(parts[0])
+			valueField.SetText(parts[1])
+			createOpts.Environment = append(createOpts.Environment[:i], createOpts.Environment[i+1:]...)
+		} else {
+			i -= len(createOpts.Environment)
+			name, value, _ := splitCredential("SetCredential", createOpts.SetCredentials[i])
+			keyField.SetText(name)
+			valueField.SetText(value)
+			createOpts.SetCredentials = append(createOpts.SetCredentials[:i], createOpts.SetCredentials[i+1:]...)
+		}
+		refresh()
+		app.SetFocus(form)
+	})
+	list.SetDoneFunc(func() {
+		app.SetFocus(form)
+	})
+	form.SetCancelFunc(func() {
+		app.SetFocus(list)
+	})
+
+	list.SetBorder(true).SetTitle("Variables (Esc to select, Enter to edit)")
+	buttons(form)
+	form.SetBorder(true).SetTitle("Environment").SetTitleAlign(tview.AlignCenter)
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, false).
+		AddItem(form, 0, 1, true)
+}
+
+func loggingForm(buttons func(*tview.Form)) *tview.Form {
+	l := &createOpts.Logging
+
+	// keep values loaded from existing Unit files selectable
+	facilities, levels := syslogFacilities, logLevels
+	if !facilities.Contains(l.SyslogFacility) {
+		facilities = append(Strings{l.SyslogFacility}, facilities...)
+	}
+	if !levels.Contains(l.LogLevelMax) {
+		levels = append(Strings{l.LogLevelMax}, levels...)
+	}
+
+	var extraFields []string
+	for _, kv := range l.LogExtraFields {
+		extraFields = append(extraFields, quoteEnv(kv))
+	}
+
+	form := tview.NewForm().
+		AddInputField("Output to:", l.StandardOutput, 40, nil, func(s string) {
+			l.StandardOutput = s
+		}).
+		AddInputField("Errors to:", l.StandardError, 40, nil, func(s string) {
+			l.StandardError = s
+		}).
+		AddInputField("Log as:", l.SyslogIdentifier, 30, nil, func(s string) {
+			l.SyslogIdentifier = s
+		}).
+		AddDropDown("Syslog facility:", facilities, facilities.IndexOf(l.SyslogFacility), func(s string, i int) {
+			l.SyslogFacility = s
+		}).
+		AddDropDown("Max log level:", levels, levels.IndexOf(l.LogLevelMax), func(s string, i int) {
+			l.LogLevelMax = s
+		}).
+		AddInputField("Rate limit interval:", l.LogRateLimitIntervalSec, 10, nil, func(s string) {
+			l.LogRateLimitIntervalSec = s
+		}).
+		AddInputField("Rate limit burst:", l.LogRateLimitBurst, 10, nil, func(s string) {
+			l.LogRateLimitBurst = s
+		}).
+		AddInputField("Extra fields:", strings.Join(extraFields, " "), 40, nil, func(s string) {
+			if kvs, err := parseEnvironment(s); err == nil {
+				l.LogExtraFields = kvs
+			}
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Logging").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func directoriesForm(buttons func(*tview.Form)) *tview.Form {
+	form := tview.NewForm()
+
+	var fields []*tview.InputField
+	for _, d := range managedDirectories {
+		dir, mode := directoryField(d.Name), directoryField(d.Name+"Mode")
+		field := tview.NewInputField().
+			SetLabel(fmt.Sprintf("%s directory:", strings.TrimSuffix(d.Name, "Directory"))).
+			SetText(*dir).
+			SetFieldWidth(30).
+			SetChangedFunc(func(s string) {
+				*dir = s
+			})
+		fields = append(fields, field)
+
+		form.
+			AddFormItem(field).
+			AddFormItem(tview.NewInputField().
+				SetLabel("  Mode:").
+				SetText(*mode).
+				SetPlaceholder("0755").
+				SetFieldWidth(5).
+				SetChangedFunc(func(s string) {
+					*mode = s
+				}))
+	}
+
+	form.
+		AddButton("Suggest", func() {
+			// directories are usually named after the service
+			for _, f := range fields {
+				if len(f.GetText()) == 0 {
+					f.SetText(serviceName())
+				}
+			}
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Directories").SetTitleAlign(tview.AlignCenter)
+	return form
+}
+
+func resourcesForm(buttons func(*tview.Form)) *tview.Form {
+	form := tview.NewForm().
+		AddInputField("Slice:", createOpts.Slice, 30, nil, func(s string) {
+			createOpts.Slice = s
+		}).
+		AddInputField("Memory limit:", createOpts.MemoryMax, 10, nil, func(s string) {
+			createOpts.MemoryMax = s
+		}).
+		AddInputField("Memory throttle:", createOpts.MemoryHigh, 10, nil, func(s string) {
+			createOpts.MemoryHigh = s
+		}).
+		AddInputField("CPU quota:", createOpts.CPUQuota, 10, nil, func(s string) {
+			createOpts.CPUQuota = s
+		}).
+		AddInputField("CPU weight:", createOpts.CPUWeight, 10, nil, func(s string) {
+			createOpts.CPUWeight = s
+		}).
+		AddInputField("IO weight:", createOpts.IOWeight, 10, nil, func(s string) {
+			createOpts.IOWeight = s
+		}).
+		AddInputField("Max tasks:", createOpts.TasksMax, 10, nil, func(s string) {
+			createOpts.TasksMax = s
+		}).
+		AddInputField("Max open files:", createOpts.LimitNOFILE, 10, nil, func(s string) {
+			createOpts.LimitNOFILE = s
+		})
+
+	buttons(form)
+	form.SetBorder(true).SetTitle("Resources").SetTitleAlign(tview.AlignCenter)
+	return form
+}
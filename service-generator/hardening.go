@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type hardeningDirective struct {
+	Name string
+	// Values accepted besides booleans
+	Values Strings
+	// List directives accept a space separated list of Values
+	List bool
+
+	// Values set by the basic and strict profiles
+	Basic  string
+	Strict string
+}
+
+var (
+	hardeningProfiles   = Strings{"none", "basic", "strict"}
+	hardeningDirectives = []hardeningDirective{
+		{Name: "ProtectSystem", Values: Strings{"full", "strict"}, Basic: "full", Strict: "strict"},
+		{Name: "ProtectHome", Values: Strings{"read-only", "tmpfs"}, Basic: "read-only", Strict: "true"},
+		{Name: "PrivateTmp", Basic: "true", Strict: "true"},
+		{Name: "NoNewPrivileges", Basic: "true", Strict: "true"},
+		{Name: "PrivateDevices", Strict: "true"},
+		{Name: "ProtectKernelTunables", Strict: "true"},
+		{Name: "ProtectKernelModules", Strict: "true"},
+		{Name: "ProtectControlGroups", Strict: "true"},
+		{Name: "RestrictNamespaces", Values: Strings{"cgroup", "ipc", "net", "mnt", "pid", "user", "uts"}, List: true, Strict: "true"},
+		{Name: "RestrictRealtime", Strict: "true"},
+		{Name: "RestrictSUIDSGID", Strict: "true"},
+		{Name: "LockPersonality", Strict: "true"},
+		{Name: "MemoryDenyWriteExecute", Strict: "true"},
+	}
+	booleans = Strings{"yes", "no", "true", "false", "on", "off", "1", "0"}
+
+	// hardeningFlags holds the directives explicitly set on the command line,
+	// which take precedence over the chosen profile
+	hardeningFlags = map[string]*string{}
+)
+
+func isHardeningDirective(name string) bool {
+	for _, d := range hardeningDirectives {
+		if d.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyHardeningProfile sets all hardening directives to the values of the
+// given profile, followed by the overrides from the command line.
+func applyHardeningProfile(profile string) {
+	createOpts.HardeningProfile = profile
+	createOpts.Hardening = map[string]string{}
+
+	for _, d := range hardeningDirectives {
+		switch profile {
+		case "basic":
+			createOpts.Hardening[d.Name] = d.Basic
+		case "strict":
+			createOpts.Hardening[d.Name] = d.Strict
+		}
+
+		if v, ok := hardeningFlags[d.Name]; ok && len(*v) > 0 {
+			createOpts.Hardening[d.Name] = *v
+		}
+	}
+}
+
+func validateHardening() error {
+	if len(createOpts.HardeningProfile) > 0 && !hardeningProfiles.Contains(createOpts.HardeningProfile) {
+		return fmt.Errorf("No such hardening profile: %s", createOpts.HardeningProfile)
+	}
+
+	for _, d := range hardeningDirectives {
+		v := createOpts.Hardening[d.Name]
+		if len(v) == 0 || booleans.Contains(v) {
+			continue
+		}
+
+		vs := []string{v}
+		if d.List {
+			// a leading '~' inverts the list
+			vs = strings.Fields(strings.TrimPrefix(v, "~"))
+		}
+		for _, s := range vs {
+			if !d.Values.Contains(s) {
+				return fmt.Errorf("Invalid value for %s: %s", d.Name, s)
+			}
+		}
+	}
+
+	return nil
+}
+
+func hardeningOptions() []*unit.UnitOption {
+	var u []*unit.UnitOption
+	for _, d := range hardeningDirectives {
+		u = append(u, &unit.UnitOption{"Service", d.Name, createOpts.Hardening[d.Name]})
+	}
+
+	return u
+}
+
+func init() {
+	createCmd.PersistentFlags().StringVar(&createOpts.HardeningProfile, "hardening", "none", "Sandboxing profile to apply (none, basic or strict)")
+	for _, d := range hardeningDirectives {
+		v := new(string)
+		hardeningFlags[d.Name] = v
+		createCmd.PersistentFlags().StringVar(v, strings.ToLower(d.Name), "", fmt.Sprintf("Override %s of the hardening profile", d.Name))
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat  string
+	importFormats = Strings{"supervisord", "procfile", "compose"}
+
+	importCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "creates Unit files from other process managers' configs",
+		Long:  `The import command creates Unit files from supervisord configs, Procfiles and docker-compose files`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename := args[0]
+			format := importFormat
+			if len(format) == 0 {
+				format = detectImportFormat(filename)
+			}
+
+			var services []importedService
+			var err error
+			switch format {
+			case "supervisord":
+				services, err = importSupervisord(filename)
+			case "procfile":
+				services, err = importProcfile(filename)
+			case "compose":
+				services, err = importCompose(filename)
+			default:
+				return fmt.Errorf("No such import format: %s (expected one of %s)", format, strings.Join(importFormats, ", "))
+			}
+			if err != nil {
+				return err
+			}
+			if len(services) == 0 {
+				return fmt.Errorf("Could not find any processes in %s", filename)
+			}
+
+			for _, s := range services {
+				if s.Skipped {
+					fmt.Printf("Skipped %s:\n", s.Name)
+					for _, u := range s.Untranslated {
+						fmt.Printf("  - %s\n", u)
+					}
+					fmt.Println()
+					continue
+				}
+
+				createOpts = s.Options
+				extraOptions = nil
+				if err := secureEnvironment(s.Name); err != nil {
+					return fmt.Errorf("%s: %s", s.Name, err)
+				}
+
+				words, _ := splitQuoted(createOpts.Exec)
+				if len(words) > 0 && !filepath.IsAbs(words[0]) {
+					s.untranslated("%s is not an absolute path, which systemd requires", words[0])
+				}
+
+				if err := writeUnit(s.Name+".service", serviceOptions()); err != nil {
+					return err
+				}
+				if len(s.Untranslated) > 0 {
+					fmt.Printf("Could not translate for %s:\n", s.Name)
+					for _, u := range s.Untranslated {
+						fmt.Printf("  - %s\n", u)
+					}
+					fmt.Println()
+				}
+			}
+
+			return nil
+		},
+	}
+)
+
+// detectImportFormat guesses the format of a file from its name.
+func detectImportFormat(filename string) string {
+	base := strings.ToLower(filepath.Base(filename))
+	switch {
+	case strings.HasPrefix(base, "procfile"):
+		return "procfile"
+	case strings.HasSuffix(base, ".yml") || strings.HasSuffix(base, ".yaml"):
+		return "compose"
+	default:
+		return "supervisord"
+	}
+}
+
+func init() {
+	importCmd.PersistentFlags().StringVarP(&importFormat, "format", "f", "", "Format of the file (supervisord, procfile or compose), detected from its name by default")
+
+	RootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type importedService struct {
+	Name    string
+	Options CreateOptions
+	// Untranslated describes everything which couldn't be mapped to systemd
+	Untranslated []string
+	// Skipped services can't be turned into a Unit file at all
+	Skipped bool
+}
+
+func newImportedService(name string) importedService {
+	return importedService{
+		Name: name,
+		Options: CreateOptions{
+			Type:     "simple",
+			Restart:  "on-failure",
+			WantedBy: "multi-user.target",
+		},
+	}
+}
+
+func (s *importedService) untranslated(format string, a ...interface{}) {
+	s.Untranslated = append(s.Untranslated, fmt.Sprintf(format, a...))
+}
+
+// joinCommand builds a command line from its words.
+func joinCommand(words []string) string {
+	var quoted []string
+	for _, w := range words {
+		quoted = append(quoted, quoteWord(w))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// escapeCommand keeps systemd from expanding variables and specifiers in a
+// command which doesn't expect them.
+func escapeCommand(s string) string {
+	return strings.NewReplacer("$", "$$", "%", "%%").Replace(s)
+}
+
+// importProcfile reads a Heroku-style Procfile. As Heroku runs all processes
+// with a shell from the application's directory, so do the generated units.
+func importProcfile(filename string) ([]importedService, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	app := filepath.Base(dir)
+
+	var res []importedService
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid Procfile line: %s", line)
+		}
+
+		s := newImportedService(app + "-" + strings.TrimSpace(kv[0]))
+		s.Options.Description = fmt.Sprintf("%s %s process", app, strings.TrimSpace(kv[0]))
+		// leave expanding variables to the shell
+		command := escapeCommand(strings.TrimSpace(kv[1]))
+		s.Options.Exec = joinCommand([]string{"/bin/sh", "-c", command})
+		s.Options.WorkingDirectory = dir
+		s.Options.Restart = "always"
+		if strings.Contains(kv[1], "$PORT") {
+			s.untranslated("$PORT is not set by systemd, add Environment=PORT=... to the Unit file")
+		}
+		res = append(res, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	return res, nil
+}
+
+// importSupervisord reads the [program:x] sections of a supervisord config.
+func importSupervisord(filename string) ([]importedService, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	var res []importedService
+	var s *importedService
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section := strings.Trim(line, "[]")
+			s = nil
+			if strings.HasPrefix(section, "program:") {
+				res = append(res, newImportedService(strings.TrimPrefix(section, "program:")))
+				s = &res[len(res)-1]
+				s.Options.Description = fmt.Sprintf("%s program", s.Name)
+			}
+			continue
+		}
+		if s == nil {
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid line in [program:%s]: %s", s.Name, line)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if strings.Contains(value, "%(") {
+			s.untranslated("%s uses supervisord's %%(name)s expansion: %s", key, value)
+		}
+
+		switch key {
+		case "command":
+			// supervisord runs the command without a shell
+			s.Options.Exec = escapeCommand(value)
+		case "directory":
+			s.Options.WorkingDirectory = value
+		case "user":
+			s.Options.User = value
+		case "environment":
+			// KEY="value",KEY2=value2
+			words, err := splitQuoted(unquotedReplace(value, ',', ' '))
+			if err != nil {
+				return nil, err
+			}
+			s.Options.Environment = append(s.Options.Environment, words...)
+		case "autostart":
+			if isFalse(value) {
+				s.Options.WantedBy = ""
+			}
+		case "autorestart":
+			switch strings.ToLower(value) {
+			case "true":
+				s.Options.Restart = "always"
+			case "false":
+				s.Options.Restart = "no"
+			case "unexpected":
+				s.Options.Restart = "on-failure"
+			}
+		case "stopwaitsecs":
+			s.Options.TimeoutStopSec = value
+		case "startsecs":
+			s.untranslated("startsecs=%s: systemd has no minimum uptime for a start to count as successful", value)
+		case "numprocs":
+			if value != "1" {
+				s.untranslated("numprocs=%s: generate a template unit and use --instances instead", value)
+			}
+		default:
+			s.untranslated("%s=%s", key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	return res, nil
+}
+
+// unquotedReplace replaces all occurrences of old outside of quotes.
+func unquotedReplace(s string, old, new rune) string {
+	var quote rune
+	return strings.Map(func(r rune) rune {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == old:
+			return new
+		}
+		return r
+	}, s)
+}
+
+type composeService struct {
+	Command     interface{} `yaml:"command"`
+	Entrypoint  interface{} `yaml:"entrypoint"`
+	WorkingDir  string      `yaml:"working_dir"`
+	Environment interface{} `yaml:"environment"`
+	User        string      `yaml:"user"`
+	Restart     string      `yaml:"restart"`
+	DependsOn   interface{} `yaml:"depends_on"`
+}
+
+// composeWords returns a docker-compose command, given as string or list.
+func composeWords(v interface{}) ([]string, error) {
+	switch c := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return splitQuoted(c)
+	case []interface{}:
+		var res []string
+		for _, w := range c {
+			res = append(res, fmt.Sprint(w))
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("Invalid command: %v", v)
+	}
+}
+
+// composeList returns a docker-compose list, which may also be given as a
+// map, in which case entries are joined with sep.
+func composeList(v interface{}, sep string) []string {
+	var res []string
+	switch l := v.(type) {
+	case []interface{}:
+		for _, e := range l {
+			res = append(res, fmt.Sprint(e))
+		}
+	case map[interface{}]interface{}:
+		for k, e := range l {
+			if e == nil || len(sep) == 0 {
+				res = append(res, fmt.Sprint(k))
+			} else {
+				res = append(res, fmt.Sprint(k)+sep+fmt.Sprint(e))
+			}
+		}
+		sort.Strings(res)
+	}
+
+	return res
+}
+
+// importCompose reads the services of a docker-compose.yml. The commands are
+// expected to be available on the host, no containers get involved.
+func importCompose(filename string) ([]importedService, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	// only version 1 files have their services on the top level
+	var raw struct {
+		Services map[string]map[string]interface{} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("Could not parse docker-compose file: %s", err)
+	}
+	var services struct {
+		Services map[string]composeService `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(b, &services); err != nil {
+		return nil, fmt.Errorf("Could not parse docker-compose file: %s", err)
+	}
+	if len(raw.Services) == 0 {
+		if err := yaml.Unmarshal(b, &raw.Services); err != nil {
+			return nil, fmt.Errorf("Could not parse docker-compose file: %s", err)
+		}
+		if err := yaml.Unmarshal(b, &services.Services); err != nil {
+			return nil, fmt.Errorf("Could not parse docker-compose file: %s", err)
+		}
+	}
+
+	var names []string
+	for name := range services.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []importedService
+	for _, name := range names {
+		cs := services.Services[name]
+		s := newImportedService(name)
+		s.Options.Description = fmt.Sprintf("%s service", name)
+
+		entrypoint, err := composeWords(cs.Entrypoint)
+		if err != nil {
+			return nil, err
+		}
+		command, err := composeWords(cs.Command)
+		if err != nil {
+			return nil, err
+		}
+		// the commands run without a shell, so nothing gets expanded
+		var words []string
+		for _, w := range append(entrypoint, command...) {
+			words = append(words, escapeCommand(w))
+		}
+		s.Options.Exec = joinCommand(words)
+		if len(s.Options.Exec) == 0 {
+			s.untranslated("No command or entrypoint, the image's default command is unknown")
+			s.Skipped = true
+		}
+
+		s.Options.WorkingDirectory = cs.WorkingDir
+		// the user may be given as user:group
+		user := strings.SplitN(cs.User, ":", 2)
+		s.Options.User = user[0]
+		if len(user) == 2 {
+			s.Options.Group = user[1]
+		}
+		s.Options.Environment = composeList(cs.Environment, "=")
+		for _, d := range composeList(cs.DependsOn, "") {
+			s.Options.After = strings.TrimSpace(s.Options.After + " " + d + ".service")
+		}
+
+		switch cs.Restart {
+		case "no":
+			s.Options.Restart = "no"
+		case "always", "unless-stopped":
+			s.Options.Restart = "always"
+		case "on-failure":
+			s.Options.Restart = "on-failure"
+		}
+
+		var keys []string
+		for k := range raw.Services[name] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			switch k {
+			case "command", "entrypoint", "working_dir", "environment", "user", "restart", "depends_on":
+			default:
+				s.untranslated("%s: %v", k, raw.Services[name][k])
+			}
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type importTest struct {
+	name         string
+	exec         string
+	user         string
+	group        string
+	restart      string
+	wantedBy     string
+	dir          string
+	env          []string
+	untranslated int
+	skipped      bool
+}
+
+func checkImport(t *testing.T, services []importedService, tests []importTest) {
+	if len(services) != len(tests) {
+		t.Fatalf("Expected %d services, got %d", len(tests), len(services))
+	}
+
+	for i, test := range tests {
+		s := services[i]
+		o := s.Options
+		if s.Name != test.name {
+			t.Errorf("Expected service %s, got %s", test.name, s.Name)
+		}
+		if o.Exec != test.exec {
+			t.Errorf("%s: expected command %s, got %s", test.name, test.exec, o.Exec)
+		}
+		if o.User != test.user || o.Group != test.group {
+			t.Errorf("%s: expected to run as %q:%q, got %q:%q", test.name, test.user, test.group, o.User, o.Group)
+		}
+		if o.Restart != test.restart || o.WantedBy != test.wantedBy {
+			t.Errorf("%s: expected Restart=%s and WantedBy=%s, got %s and %s", test.name, test.restart, test.wantedBy, o.Restart, o.WantedBy)
+		}
+		if o.WorkingDirectory != test.dir {
+			t.Errorf("%s: expected working directory %s, got %s", test.name, test.dir, o.WorkingDirectory)
+		}
+		if !reflect.DeepEqual(o.Environment, test.env) {
+			t.Errorf("%s: expected environment %q, got %q", test.name, test.env, o.Environment)
+		}
+		if len(s.Untranslated) != test.untranslated {
+			t.Errorf("%s: expected %d untranslated settings, got %q", test.name, test.untranslated, s.Untranslated)
+		}
+		if s.Skipped != test.skipped {
+			t.Errorf("%s: expected skipped to be %v", test.name, test.skipped)
+		}
+	}
+}
+
+func TestImportProcfile(t *testing.T) {
+	services, err := importProcfile("testdata/import/shop/Procfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := filepath.Abs("testdata/import/shop")
+
+	checkImport(t, services, []importTest{
+		{name: "shop-web", exec: `/bin/sh -c "bundle exec puma -p $$PORT"`, restart: "always", wantedBy: "multi-user.target", dir: dir, untranslated: 1},
+		{name: "shop-worker", exec: `/bin/sh -c "bundle exec sidekiq -c 5%%"`, restart: "always", wantedBy: "multi-user.target", dir: dir},
+	})
+}
+
+func TestImportSupervisord(t *testing.T) {
+	services, err := importSupervisord("testdata/import/supervisord.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkImport(t, services, []importTest{
+		{name: "api", exec: "/usr/bin/api --port 8080 --name %%(program_name)s", user: "www", restart: "on-failure", wantedBy: "multi-user.target", dir: "/srv/api", env: []string{"API_ENV=production", "LANG=C.UTF-8"}, untranslated: 2},
+		{name: "cron", exec: "/usr/sbin/cron -f -L $$HOME", restart: "always"},
+	})
+	if services[0].Options.TimeoutStopSec != "30" {
+		t.Errorf("Expected stopwaitsecs to become TimeoutStopSec, got %q", services[0].Options.TimeoutStopSec)
+	}
+}
+
+func TestImportCompose(t *testing.T) {
+	services, err := importCompose("testdata/import/docker-compose.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkImport(t, services, []importTest{
+		{name: "db", restart: "on-failure", wantedBy: "multi-user.target", untranslated: 2, skipped: true},
+		{name: "web", exec: "/usr/bin/env /usr/bin/web --port 8080", user: "1000", group: "1000", restart: "always", wantedBy: "multi-user.target", dir: "/srv/web", env: []string{"LANG=C.UTF-8", "PRICE=5$"}, untranslated: 1},
+	})
+	if services[1].Options.After != "db.service" {
+		t.Errorf("Expected web to be ordered after db.service, got %q", services[1].Options.After)
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"Procfile", "procfile"},
+		{"/srv/app/Procfile", "procfile"},
+		{"docker-compose.yml", "compose"},
+		{"supervisord.conf", "supervisord"},
+	}
+
+	for _, test := range tests {
+		if f := detectImportFormat(test.filename); f != test.want {
+			t.Errorf("detectImportFormat(%q) = %q, expected %q", test.filename, f, test.want)
+		}
+	}
+}
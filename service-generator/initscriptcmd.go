@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// lsbFacilities maps LSB boot facilities to their systemd counterparts
+	lsbFacilities = map[string]string{
+		"$local_fs":  "local-fs.target",
+		"$remote_fs": "remote-fs.target",
+		"$network":   "network-online.target",
+		"$named":     "nss-lookup.target",
+		"$portmap":   "rpcbind.target",
+		"$time":      "time-sync.target",
+		"$syslog":    "",
+		"$all":       "",
+	}
+
+	// stopCommands are the arguments daemons take to stop themselves
+	stopCommands = Strings{"stop", "quit", "shutdown"}
+
+	shellAssignment  = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	shellVariable    = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+	shellRedirection = regexp.MustCompile(`^[0-9]*[<>]`)
+	shellSource      = regexp.MustCompile(`(?:^|[;&|]\s*)(?:\.|source)\s+(/etc/(?:default|sysconfig)/\S+)`)
+
+	// argumentVariable matches the conventional names of variables holding a
+	// daemon's arguments, like DAEMON_ARGS or OPTIONS
+	argumentVariable = regexp.MustCompile(`(?:^|_)(?:ARGS|OPTS|OPTIONS)$`)
+
+	initScriptCmd = &cobra.Command{
+		Use:   "from-initscript <init-script>",
+		Short: "creates a Unit file from a SysV init script",
+		Long:  `The from-initscript command creates a native Unit file from a SysV init script and its LSB header`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := importInitScript(args[0])
+			if err != nil {
+				return err
+			}
+
+			createOpts = s.Options
+			extraOptions = nil
+			if err := secureEnvironment(s.Name); err != nil {
+				return err
+			}
+			if err := writeUnit(s.Name+".service", serviceOptions()); err != nil {
+				return err
+			}
+			if len(s.Untranslated) > 0 {
+				fmt.Printf("Could not translate for %s:\n", s.Name)
+				for _, u := range s.Untranslated {
+					fmt.Printf("  - %s\n", u)
+				}
+			}
+			return nil
+		},
+	}
+)
+
+// runlevelTarget returns the target corresponding to LSB Default-Start
+// runlevels.
+func runlevelTarget(runlevels []string) string {
+	rl := Strings(runlevels)
+	switch {
+	case rl.Contains("2") || rl.Contains("3") || rl.Contains("4"):
+		return "multi-user.target"
+	case rl.Contains("5"):
+		return "graphical.target"
+	case rl.Contains("S"):
+		return "sysinit.target"
+	default:
+		return ""
+	}
+}
+
+// readShellScript returns the lines of a shell script with continued lines
+// joined, ignoring comments except for the LSB header.
+func readShellScript(filename string) ([]string, map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not open file: %s", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	header := map[string]string{}
+	inHeader := false
+	continued := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "### BEGIN INIT INFO"):
+			inHeader = true
+			continue
+		case strings.HasPrefix(trimmed, "### END INIT INFO"):
+			inHeader = false
+			continue
+		case inHeader:
+			kv := strings.SplitN(strings.TrimLeft(trimmed, "# "), ":", 2)
+			if len(kv) == 2 {
+				header[kv[0]] = strings.TrimSpace(kv[1])
+			}
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			continue
+		}
+
+		if strings.HasSuffix(trimmed, "\\") {
+			continued += strings.TrimSuffix(trimmed, "\\") + " "
+			continue
+		}
+		lines = append(lines, continued+trimmed)
+		continued = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("Could not read file: %s", err)
+	}
+
+	return lines, header, nil
+}
+
+// shellCommand strips redirections and everything following the first
+// command separator from the words of a shell line.
+func shellCommand(words []string) []string {
+	var res []string
+	for _, w := range words {
+		switch {
+		case Strings{"||", "&&", ";", "|", "&"}.Contains(w):
+			return res
+		case shellRedirection.MatchString(w):
+			continue
+		}
+		res = append(res, w)
+	}
+
+	return res
+}
+
+// parseStartStopDaemon parses a Debian start-stop-daemon invocation.
+func parseStartStopDaemon(s *importedService, words []string) {
+	var exec, startas, pidfile string
+	var args []string
+	background, makePidfile := false, false
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		value := func() string {
+			if kv := strings.SplitN(w, "=", 2); len(kv) == 2 {
+				return kv[1]
+			}
+			if i+1 < len(words) {
+				i++
+				return words[i]
+			}
+			return ""
+		}
+
+		switch strings.SplitN(w, "=", 2)[0] {
+		case "--exec", "-x":
+			exec = value()
+		case "--startas", "-a":
+			startas = value()
+		case "--pidfile", "-p":
+			pidfile = value()
+		case "--chuid", "-c":
+			ug := strings.SplitN(value(), ":", 2)
+			s.Options.User = ug[0]
+			if len(ug) == 2 {
+				s.Options.Group = ug[1]
+			}
+		case "--chdir", "-d":
+			s.Options.WorkingDirectory = value()
+		case "--background", "-b":
+			background = true
+		case "--make-pidfile", "-m":
+			makePidfile = true
+		case "--name", "-n", "--user", "-u", "--signal", "-s", "--retry", "-R", "--nicelevel", "-N":
+			value()
+		case "--":
+			args = words[i+1:]
+			i = len(words)
+		}
+	}
+
+	if len(startas) > 0 {
+		exec = startas
+	}
+	s.Options.Exec = joinCommand(append([]string{exec}, args...))
+
+	switch {
+	case background || makePidfile:
+		// start-stop-daemon daemonizes the process for us
+		s.Options.Type = "simple"
+	case len(pidfile) > 0:
+		s.Options.Type = "forking"
+		s.Options.PIDFile = pidfile
+	default:
+		s.Options.Type = "forking"
+		s.untranslated("Could not tell whether %s daemonizes itself, assumed Type=forking", exec)
+	}
+}
+
+// parseDaemon parses an invocation of the Red Hat daemon() shell function.
+func parseDaemon(s *importedService, words []string) {
+	i := 0
+	for ; i < len(words); i++ {
+		w := words[i]
+		kv := strings.SplitN(w, "=", 2)
+		switch {
+		case kv[0] == "--user" && len(kv) == 2:
+			s.Options.User = kv[1]
+		case kv[0] == "--pidfile" && len(kv) == 2:
+			s.Options.PIDFile = kv[1]
+		case (kv[0] == "--user" || kv[0] == "--pidfile") && i+1 < len(words):
+			i++
+			if kv[0] == "--user" {
+				s.Options.User = words[i]
+			} else {
+				s.Options.PIDFile = words[i]
+			}
+		case strings.HasPrefix(w, "-") || strings.HasPrefix(w, "+"):
+			// --check, --force and nice levels
+		default:
+			s.Options.Exec = joinCommand(words[i:])
+			s.Options.Type = "forking"
+			return
+		}
+	}
+}
+
+// importInitScript translates a SysV init script into service options.
+func importInitScript(filename string) (importedService, error) {
+	lines, header, err := readShellScript(filename)
+	if err != nil {
+		return importedService{}, err
+	}
+
+	name := filepath.Base(filename)
+	if provides := strings.Fields(header["Provides"]); len(provides) > 0 {
+		name = provides[0]
+	}
+	s := newImportedService(name)
+	s.Options.Description = header["Short-Description"]
+	if len(s.Options.Description) == 0 {
+		s.Options.Description = fmt.Sprintf("%s service", name)
+	}
+
+	// Dependencies
+	var after, wants []string
+	for _, dep := range strings.Fields(header["Required-Start"] + " " + header["Should-Start"]) {
+		target, ok := lsbFacilities[dep]
+		switch {
+		case !ok && strings.HasPrefix(dep, "$"):
+			s.untranslated("Unknown boot facility %s", dep)
+		case !ok:
+			after = append(after, dep+".service")
+		case len(target) > 0:
+			after = append(after, target)
+			// nothing pulls in network-online.target by default
+			if target == "network-online.target" {
+				wants = append(wants, target)
+			}
+		}
+	}
+	s.Options.After = strings.Join(after, " ")
+	s.Options.Wants = strings.Join(wants, " ")
+	s.Options.WantedBy = runlevelTarget(strings.Fields(header["Default-Start"]))
+	if len(header["X-Start-Before"]) > 0 {
+		s.untranslated("X-Start-Before: %s", header["X-Start-Before"])
+	}
+
+	// Variables and commands
+	sourced := false
+	for _, line := range lines {
+		sourced = sourced || shellSource.MatchString(line)
+	}
+	vars := map[string]string{"NAME": name}
+	expand := func(s string) string {
+		return shellVariable.ReplaceAllStringFunc(s, func(v string) string {
+			name := shellVariable.FindStringSubmatch(v)[1]
+			if value, ok := vars[name]; ok {
+				return value
+			}
+			return v
+		})
+	}
+	// arguments a defaults file may set are left to systemd to expand
+	expandCommand := func(s string) string {
+		return shellVariable.ReplaceAllStringFunc(s, func(v string) string {
+			name := shellVariable.FindStringSubmatch(v)[1]
+			if sourced && argumentVariable.MatchString(name) {
+				return "$" + name
+			}
+			return expand(v)
+		})
+	}
+
+	daemon := ""
+	for _, line := range lines {
+		if m := shellAssignment.FindStringSubmatch(line); m != nil {
+			if _, ok := vars[m[1]]; !ok {
+				words, err := splitQuoted(expand(m[2]))
+				if err == nil {
+					vars[m[1]] = strings.Join(words, " ")
+				}
+			}
+			continue
+		}
+		if m := shellSource.FindStringSubmatch(line); m != nil {
+			// the defaults file is optional, just like in the script
+			s.Options.EnvironmentFiles = append(s.Options.EnvironmentFiles, "-"+expand(m[1]))
+			continue
+		}
+
+		words, err := splitQuoted(expandCommand(line))
+		if err != nil {
+			continue
+		}
+		words = shellCommand(words)
+		for i, w := range words {
+			switch {
+			case w == "start-stop-daemon" && len(s.Options.Exec) == 0 &&
+				(Strings(words).Contains("--start") || Strings(words).Contains("-S")):
+				parseStartStopDaemon(&s, words[i+1:])
+				daemon = strings.Trim(strings.SplitN(s.Options.Exec, " ", 2)[0], `"`)
+			case w == "daemon" && i == 0 && len(s.Options.Exec) == 0:
+				parseDaemon(&s, words[i+1:])
+				daemon = strings.Trim(strings.SplitN(s.Options.Exec, " ", 2)[0], `"`)
+			case len(daemon) > 0 && w == daemon && i+1 < len(words) && len(s.Options.ExecStop) == 0:
+				// daemons which are told to stop by calling them again
+				for _, arg := range words[i+1:] {
+					if stopCommands.Contains(arg) {
+						s.Options.ExecStop = []string{joinCommand(words[i:])}
+					}
+				}
+			}
+		}
+	}
+
+	if len(s.Options.Exec) == 0 {
+		return s, fmt.Errorf("Could not find a start-stop-daemon or daemon invocation in %s", filename)
+	}
+	// the script's defaults apply unless the defaults file overrides them
+	var names []string
+	for name, value := range vars {
+		commands := s.Options.Exec + " " + strings.Join(s.Options.ExecStop, " ")
+		if sourced && argumentVariable.MatchString(name) && len(value) > 0 && strings.Contains(commands, "$"+name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.Options.Environment = append(s.Options.Environment, name+"="+vars[name])
+	}
+
+	if strings.Contains(s.Options.Exec, "$") && len(s.Options.EnvironmentFiles) == 0 {
+		s.untranslated("ExecStart refers to variables which are not set: %s", s.Options.Exec)
+	}
+	if len(s.Options.ExecStop) == 0 {
+		s.untranslated("No explicit stop command found, systemd will send SIGTERM to %s", daemon)
+	}
+
+	return s, nil
+}
+
+func init() {
+	RootCmd.AddCommand(initScriptCmd)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportInitScript(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		exec     string
+		execStop []string
+		typ      string
+		pidFile  string
+		user     string
+		group    string
+		after    string
+		wants    string
+		wantedBy string
+		env      []string
+		envFiles []string
+	}{
+		{
+			filename: "testdata/init.d/foo",
+			name:     "foo",
+			// arguments come from the defaults file, if it sets them
+			exec:     "/usr/sbin/foo $DAEMON_ARGS",
+			typ:      "forking",
+			pidFile:  "/run/foo.pid",
+			user:     "foo",
+			group:    "daemon",
+			after:    "remote-fs.target network-online.target postgresql.service",
+			wants:    "network-online.target",
+			wantedBy: "multi-user.target",
+			env:      []string{"DAEMON_ARGS=--port 8080"},
+			envFiles: []string{"-/etc/default/foo"},
+		},
+		{
+			filename: "testdata/init.d/bar",
+			name:     "bar",
+			// without a defaults file, the script's values are all there is
+			exec:     "/usr/bin/bar -v",
+			execStop: []string{"/usr/bin/bar shutdown"},
+			typ:      "forking",
+			pidFile:  "/var/run/bar.pid",
+			user:     "bar",
+			after:    "local-fs.target time-sync.target",
+			wantedBy: "multi-user.target",
+		},
+	}
+
+	for _, test := range tests {
+		s, err := importInitScript(test.filename)
+		if err != nil {
+			t.Errorf("importInitScript(%s): %s", test.filename, err)
+			continue
+		}
+
+		o := s.Options
+		got := []interface{}{s.Name, o.Exec, o.ExecStop, o.Type, o.PIDFile, o.User, o.Group, o.After, o.Wants, o.WantedBy, o.Environment, o.EnvironmentFiles}
+		want := []interface{}{test.name, test.exec, test.execStop, test.typ, test.pidFile, test.user, test.group, test.after, test.wants, test.wantedBy, test.env, test.envFiles}
+		for i := range got {
+			if !reflect.DeepEqual(got[i], want[i]) {
+				t.Errorf("importInitScript(%s): got %#v, expected %#v", test.filename, got[i], want[i])
+			}
+		}
+	}
+
+	if _, err := importInitScript("testdata/init.d/none"); err == nil {
+		t.Errorf("Expected an error for a script which doesn't start a daemon")
+	}
+}
+
+func TestRunlevelTarget(t *testing.T) {
+	tests := []struct {
+		runlevels []string
+		want      string
+	}{
+		{[]string{"2", "3", "4", "5"}, "multi-user.target"},
+		{[]string{"5"}, "graphical.target"},
+		{[]string{"S"}, "sysinit.target"},
+		{nil, ""},
+	}
+
+	for _, test := range tests {
+		if target := runlevelTarget(test.runlevels); target != test.want {
+			t.Errorf("runlevelTarget(%q) = %s, expected %s", test.runlevels, target, test.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type InstallOptions struct {
+	Install bool   `json:"-"`
+	UnitDir string `json:"unitdir"`
+	Enable  bool   `json:"enable"`
+	Start   bool   `json:"start"`
+}
+
+// unitDir returns the directory generated units get written to. The default
+// unit directory is relative to --root.
+func unitDir() string {
+	switch {
+	case !createOpts.Install.Install:
+		return ""
+	case len(createOpts.Install.UnitDir) > 0:
+		return createOpts.Install.UnitDir
+	default:
+		return filepath.Join(unitRoot, defaultUnitDir())
+	}
+}
+
+// defaultUnitDir returns the directory administrators install units to.
+func defaultUnitDir() string {
+	if !userMode {
+		return "/etc/systemd/system"
+	}
+
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "systemd", "user")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "systemd", "user")
+}
+
+// installUnits makes systemd pick up the freshly written units and, if
+// requested, enables and starts them. Services activated by a timer or
+// socket get enabled and started through those.
+func installUnits(name, service string) error {
+	if err := createAccounts(name); err != nil {
+		return err
+	}
+
+	var units []string
+	if createOpts.Timer.Enabled {
+		units = append(units, name+".timer")
+	}
+	if createOpts.Socket.Enabled {
+		units = append(units, name+".socket")
+	}
+	if createOpts.Instances > 0 {
+		units = append(units, instances(name)...)
+	}
+	if len(units) == 0 && !isTemplate() {
+		units = append(units, service)
+	}
+
+	if len(unitRoot) > 0 {
+		return enableOffline(units)
+	}
+
+	conn, err := connection()
+	if err != nil {
+		return fmt.Errorf("Can't connect to systemd: %s", err)
+	}
+
+	if err := conn.Reload(); err != nil {
+		return fmt.Errorf("Could not reload systemd: %s", err)
+	}
+	fmt.Println("Reloaded systemd manager configuration")
+
+	if createOpts.Install.Enable {
+		_, changes, err := conn.EnableUnitFiles(units, false, true)
+		if err != nil {
+			return fmt.Errorf("Could not enable units: %s", err)
+		}
+		for _, c := range changes {
+			fmt.Printf("Enabled: %s %s -> %s\n", c.Type, c.Filename, c.Destination)
+		}
+	}
+
+	if createOpts.Install.Start {
+		for _, u := range units {
+			ch := make(chan string)
+			if _, err := conn.StartUnit(u, "replace", ch); err != nil {
+				return fmt.Errorf("Could not start %s: %s", u, err)
+			}
+
+			result := <-ch
+			if result != "done" {
+				return fmt.Errorf("Starting %s failed: %s", u, result)
+			}
+			fmt.Printf("Started %s: %s\n", u, result)
+		}
+	}
+
+	return nil
+}
+
+// enableOffline enables units below --root, for which there's no service
+// manager to talk to.
+func enableOffline(units []string) error {
+	if !createOpts.Install.Enable {
+		return nil
+	}
+
+	args := []string{"--root=" + unitRoot, "enable"}
+	if userMode {
+		args = append([]string{"--global"}, args...)
+	}
+	out, err := exec.Command("systemctl", append(args, units...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Could not enable units: %s", strings.TrimSpace(string(out)))
+	}
+	fmt.Print(string(out))
+
+	return nil
+}
+
+func validateInstall() error {
+	if createOpts.Install.Start && len(unitRoot) > 0 {
+		return fmt.Errorf("Can't start units below --root %s, no service manager runs for it", unitRoot)
+	}
+
+	return nil
+}
+
+func init() {
+	createCmd.PersistentFlags().BoolVar(&createOpts.Install.Install, "install", false, "Install the generated units and reload systemd")
+	createCmd.PersistentFlags().StringVar(&createOpts.Install.UnitDir, "unitdir", "", "Directory to install the generated units to, implies --install (default /etc/systemd/system or ~/.config/systemd/user)")
+	createCmd.PersistentFlags().BoolVar(&createOpts.Install.Enable, "enable", false, "Enable the installed units (implies --install)")
+	createCmd.PersistentFlags().BoolVar(&createOpts.Install.Start, "start", false, "Start the installed units (implies --install)")
+}
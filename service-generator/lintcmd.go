@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+	"github.com/spf13/cobra"
+)
+
+type Problem struct {
+	File    string `json:"file"`
+	Section string `json:"section,omitempty"`
+	Option  string `json:"option,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	switch {
+	case len(p.Option) > 0:
+		return fmt.Sprintf("%s: [%s] %s: %s", p.File, p.Section, p.Option, p.Message)
+	case len(p.Section) > 0:
+		return fmt.Sprintf("%s: [%s]: %s", p.File, p.Section, p.Message)
+	default:
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+}
+
+var (
+	lintJSON bool
+
+	// execOptions take a command line
+	execOptions = Strings{"ExecStart", "ExecStartPre", "ExecStartPost", "ExecCondition", "ExecReload", "ExecStop", "ExecStopPre", "ExecStopPost"}
+
+	lintCmd = &cobra.Command{
+		Use:   "lint <unit-file>...",
+		Short: "checks Unit files for problems",
+		Long:  `The lint command checks Unit files for problems without needing a running systemd`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems := []Problem{}
+			for _, filename := range args {
+				opts, err := readUnit(filename)
+				if err != nil {
+					problems = append(problems, Problem{File: filename, Message: err.Error()})
+					continue
+				}
+				problems = append(problems, lintUnit(filename, opts)...)
+			}
+
+			if lintJSON {
+				b, err := json.MarshalIndent(problems, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+			} else {
+				for _, p := range problems {
+					fmt.Println(p)
+				}
+			}
+
+			if len(problems) > 0 {
+				// the problems have already been reported
+				cmd.SilenceErrors = true
+				return fmt.Errorf("Found %d problems", len(problems))
+			}
+			return nil
+		},
+	}
+)
+
+// lintUnit checks the options of a Unit file for problems.
+func lintUnit(filename string, opts []*unit.UnitOption) []Problem {
+	var problems []Problem
+	report := func(section, option, format string, a ...interface{}) {
+		problems = append(problems, Problem{filename, section, option, fmt.Sprintf(format, a...)})
+	}
+
+	name := filepath.Base(filename)
+	typeSection, typed := unitSections[filepath.Ext(name)]
+	values := map[string]string{}
+	for _, opt := range opts {
+		values[opt.Section+"."+opt.Name] = opt.Value
+	}
+
+	reported := Strings{}
+	for _, opt := range opts {
+		if strings.HasPrefix(opt.Section, "X-") || strings.HasPrefix(opt.Name, "X-") {
+			// vendor extensions
+			continue
+		}
+
+		// Section checks
+		_, known := sectionDirectives[opt.Section]
+		valid := opt.Section == "Unit" || opt.Section == "Install" || !typed || opt.Section == typeSection
+		if !known || !valid {
+			if !reported.Contains(opt.Section) {
+				reported = append(reported, opt.Section)
+				if !known {
+					report(opt.Section, "", "Unknown section")
+				} else {
+					report(opt.Section, "", "Section is not valid in %s files", filepath.Ext(name))
+				}
+			}
+			continue
+		}
+
+		// Directive checks
+		if !isKnownDirective(opt.Section, opt.Name) {
+			if s := suggestDirective(opt.Section, opt.Name); len(s) > 0 {
+				report(opt.Section, opt.Name, "Unknown option, did you mean %s?", s)
+			} else {
+				report(opt.Section, opt.Name, "Unknown option")
+			}
+			continue
+		}
+		if len(opt.Value) == 0 {
+			// resets a previous assignment
+			continue
+		}
+
+		// Value checks
+		switch {
+		case execOptions.Contains(opt.Name):
+			c, err := parseCommand(opt.Value)
+			if err != nil {
+				report(opt.Section, opt.Name, "%s", err)
+				break
+			}
+			if !filepath.IsAbs(c.Executable) && !strings.HasPrefix(c.Executable, "%") {
+				report(opt.Section, opt.Name, "Executable needs to be an absolute path: %s", c.Executable)
+			}
+		case opt.Section == "Service" && opt.Name == "Type":
+			if !types.Contains(opt.Value) {
+				report(opt.Section, opt.Name, "No such service type: %s (expected one of %s)", opt.Value, strings.Join(types, ", "))
+			}
+		case opt.Section == "Service" && opt.Name == "Restart":
+			if !restarts.Contains(opt.Value) {
+				report(opt.Section, opt.Name, "No such restart type: %s (expected one of %s)", opt.Value, strings.Join(restarts, ", "))
+			}
+		case opt.Name == "BindIPv6Only":
+			if !bindIPv6Onlys.Contains(opt.Value) {
+				report(opt.Section, opt.Name, "No such mode: %s (expected one of %s)", opt.Value, strings.Join(bindIPv6Onlys, ", "))
+			}
+		case opt.Name == "OnCalendar":
+			if err := validateCalendar(opt.Value); err != nil {
+				report(opt.Section, opt.Name, "%s", err)
+			}
+		case timeSpanDirectives.Contains(opt.Name):
+			if _, err := parseTimeSpan(opt.Value); err != nil {
+				report(opt.Section, opt.Name, "%s", err)
+			}
+		}
+	}
+
+	// Consistency checks
+	if values["Service.Type"] == "forking" && len(values["Service.PIDFile"]) == 0 {
+		report("Service", "Type", "Forking services should set a PIDFile, so systemd can find the main process")
+	}
+	if typeSection == "Service" && len(values["Service.ExecStart"]) == 0 && values["Service.Type"] != "oneshot" {
+		report("Service", "", "Missing ExecStart")
+	}
+
+	return problems
+}
+
+func init() {
+	lintCmd.PersistentFlags().BoolVar(&lintJSON, "json", false, "Report problems as JSON")
+
+	RootCmd.AddCommand(lintCmd)
+}
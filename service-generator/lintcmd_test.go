@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintUnit(t *testing.T) {
+	tests := []struct {
+		filename string
+		opts     []string
+		problems []string
+	}{
+		{
+			"app.service",
+			[]string{"Unit.Description=App", "Service.ExecStart=/usr/bin/app", "Install.WantedBy=multi-user.target"},
+			nil,
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=/usr/bin/app", "X-Vendor.Foo=bar", "Service.X-Foo=bar"},
+			nil,
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=app"},
+			[]string{"app.service: [Service] ExecStart: Executable needs to be an absolute path: app"},
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=%h/bin/app", "Service.ExecStartPre="},
+			nil,
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=/usr/bin/app", "Service.Type=forking"},
+			[]string{"app.service: [Service] Type: Forking services should set a PIDFile, so systemd can find the main process"},
+		},
+		{
+			"app.service",
+			[]string{"Service.Type=oneshot", "Service.ExecStartPre=/usr/bin/true"},
+			nil,
+		},
+		{
+			"app.service",
+			[]string{"Service.Type=simple"},
+			[]string{"app.service: [Service]: Missing ExecStart"},
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=/usr/bin/app", "Service.Type=fancy", "Service.Restart=sometimes"},
+			[]string{
+				"app.service: [Service] Type: No such service type: fancy (expected one of simple, exec, forking, oneshot, dbus, notify, notify-reload, idle)",
+				"app.service: [Service] Restart: No such restart type: sometimes (expected one of no, always, on-success, on-failure, on-abnormal, on-abort, on-watchdog)",
+			},
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=/usr/bin/app", "Foo.Bar=baz", "Foo.Baz=bar", "Timer.OnCalendar=daily"},
+			[]string{
+				"app.service: [Foo]: Unknown section",
+				"app.service: [Timer]: Section is not valid in .service files",
+			},
+		},
+		{
+			"app.service",
+			[]string{"Service.ExecStart=/usr/bin/app", "Service.Restrt=always", "Service.FooBarBaz=1"},
+			[]string{
+				"app.service: [Service] Restrt: Unknown option, did you mean Restart?",
+				"app.service: [Service] FooBarBaz: Unknown option",
+			},
+		},
+		{
+			"app.timer",
+			[]string{"Timer.OnCalendar=someday", "Timer.AccuracySec=5 parsecs"},
+			[]string{
+				"app.timer: [Timer] OnCalendar: " + errorString(validateCalendar("someday")),
+				"app.timer: [Timer] AccuracySec: " + errorString(timeSpanError("5 parsecs")),
+			},
+		},
+		{
+			"app.socket",
+			[]string{"Socket.ListenStream=8080", "Socket.BindIPv6Only=maybe"},
+			[]string{"app.socket: [Socket] BindIPv6Only: No such mode: maybe (expected one of default, both, ipv6-only)"},
+		},
+	}
+
+	for _, test := range tests {
+		var problems []string
+		for _, p := range lintUnit(test.filename, testOptions(test.opts...)) {
+			problems = append(problems, p.String())
+		}
+		if !reflect.DeepEqual(problems, test.problems) {
+			t.Errorf("lintUnit(%s, %v): expected %q, got %q", test.filename, test.opts, test.problems, problems)
+		}
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func timeSpanError(s string) error {
+	_, err := parseTimeSpan(s)
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type LoggingOptions struct {
+	StandardOutput string `json:"standardoutput"`
+	StandardError  string `json:"standarderror"`
+
+	SyslogIdentifier string `json:"syslogidentifier"`
+	SyslogFacility   string `json:"syslogfacility"`
+	LogLevelMax      string `json:"loglevelmax"`
+
+	LogRateLimitIntervalSec string `json:"logratelimitintervalsec"`
+	LogRateLimitBurst       string `json:"logratelimitburst"`
+
+	LogExtraFields []string `json:"logextrafields"`
+}
+
+var (
+	outputs          = Strings{"", "inherit", "null", "tty", "journal", "kmsg", "journal+console", "kmsg+console", "socket"}
+	outputPrefixes   = Strings{"file:", "append:", "truncate:", "fd:"}
+	syslogFacilities = Strings{"", "kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+		"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+	logLevels = Strings{"", "emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+	// journalField matches the names of user-defined journal fields
+	journalField = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_]*$`)
+)
+
+func validateOutput(name, value string) error {
+	if outputs.Contains(value) {
+		return nil
+	}
+	for _, p := range outputPrefixes {
+		if !strings.HasPrefix(value, p) {
+			continue
+		}
+		path := strings.TrimPrefix(value, p)
+		if p != "fd:" && !filepath.IsAbs(path) {
+			return fmt.Errorf("Invalid value for %s: %s needs to be an absolute path", name, path)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Invalid value for %s: %s (expected one of %s or a file:, append:, truncate: or fd: target)", name, value, strings.Join(outputs[1:], ", "))
+}
+
+func validateLogging() error {
+	l := createOpts.Logging
+	if err := validateOutput("StandardOutput", l.StandardOutput); err != nil {
+		return err
+	}
+	if err := validateOutput("StandardError", l.StandardError); err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(l.SyslogIdentifier, " \t\n") {
+		return fmt.Errorf("Invalid value for SyslogIdentifier: %s (must not contain whitespace)", l.SyslogIdentifier)
+	}
+	if !syslogFacilities.Contains(l.SyslogFacility) {
+		return fmt.Errorf("No such syslog facility: %s (expected one of %s)", l.SyslogFacility, strings.Join(syslogFacilities[1:], ", "))
+	}
+	if n, err := strconv.Atoi(l.LogLevelMax); !logLevels.Contains(l.LogLevelMax) && (err != nil || n < 0 || n > 7) {
+		return fmt.Errorf("No such log level: %s (expected one of %s or 0 to 7)", l.LogLevelMax, strings.Join(logLevels[1:], ", "))
+	}
+
+	if err := validateTimeSpan("LogRateLimitIntervalSec", l.LogRateLimitIntervalSec); err != nil {
+		return err
+	}
+	if len(l.LogRateLimitBurst) > 0 {
+		if _, err := strconv.ParseUint(l.LogRateLimitBurst, 10, 32); err != nil {
+			return fmt.Errorf("Invalid value for LogRateLimitBurst: %s (expected a number)", l.LogRateLimitBurst)
+		}
+	}
+
+	for _, kv := range l.LogExtraFields {
+		k, _, err := splitEnv(kv)
+		if err != nil || !journalField.MatchString(k) {
+			return fmt.Errorf("Invalid journal field: %s (expected KEY=VALUE with an uppercase KEY)", kv)
+		}
+	}
+
+	return nil
+}
+
+func loggingOptions() []*unit.UnitOption {
+	l := createOpts.Logging
+	u := []*unit.UnitOption{
+		&unit.UnitOption{"Service", "StandardOutput", l.StandardOutput},
+		&unit.UnitOption{"Service", "StandardError", l.StandardError},
+		&unit.UnitOption{"Service", "SyslogIdentifier", l.SyslogIdentifier},
+		&unit.UnitOption{"Service", "SyslogFacility", l.SyslogFacility},
+		&unit.UnitOption{"Service", "LogLevelMax", l.LogLevelMax},
+		&unit.UnitOption{"Service", "LogRateLimitIntervalSec", l.LogRateLimitIntervalSec},
+		&unit.UnitOption{"Service", "LogRateLimitBurst", l.LogRateLimitBurst},
+	}
+	for _, kv := range l.LogExtraFields {
+		u = append(u, &unit.UnitOption{"Service", "LogExtraFields", quoteEnv(kv)})
+	}
+
+	return stripEmptyOptions(u)
+}
+
+func init() {
+	l := &createOpts.Logging
+	createCmd.PersistentFlags().StringVar(&l.StandardOutput, "standardoutput", "", "Where to send the service's output (journal, null, file:/path, append:/path, ...)")
+	createCmd.PersistentFlags().StringVar(&l.StandardError, "standarderror", "", "Where to send the service's error output (journal, null, file:/path, append:/path, ...)")
+	createCmd.PersistentFlags().StringVar(&l.SyslogIdentifier, "syslogidentifier", "", "Name to log the service's messages with (default is the executable's name)")
+	createCmd.PersistentFlags().StringVar(&l.SyslogFacility, "syslogfacility", "", "Syslog facility to log with (daemon, local0, ...)")
+	createCmd.PersistentFlags().StringVar(&l.LogLevelMax, "loglevelmax", "", "Drop messages less severe than this level (emerg, alert, crit, err, warning, notice, info or debug)")
+	createCmd.PersistentFlags().StringVar(&l.LogRateLimitIntervalSec, "logratelimitintervalsec", "", "Interval for the log rate limit")
+	createCmd.PersistentFlags().StringVar(&l.LogRateLimitBurst, "logratelimitburst", "", "Messages allowed per log rate limit interval")
+	createCmd.PersistentFlags().StringArrayVar(&l.LogExtraFields, "logextrafield", nil, "Additional journal field to log with (KEY=VALUE, repeatable)")
+}
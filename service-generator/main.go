@@ -43,6 +43,15 @@ func (s Strings) Contains(n string) bool {
 	return false
 }
 
+// Alternatives returns the strings as a list like "a, b or c".
+func (s Strings) Alternatives() string {
+	if len(s) < 2 {
+		return strings.Join(s, "")
+	}
+
+	return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1]
+}
+
 func stripEmptyOptions(options []*unit.UnitOption) []*unit.UnitOption {
 	var opts []*unit.UnitOption
 	for _, opt := range options {
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	overrideName string
+	overrideDir  string
+
+	// listOptions can be assigned multiple times, with an empty assignment
+	// resetting all previous ones
+	listOptions = Strings{
+		"After", "Before", "Wants", "Requires", "Requisite", "BindsTo", "PartOf", "Conflicts",
+		"WantedBy", "RequiredBy", "Also",
+		"ExecStart", "ExecStartPre", "ExecStartPost", "ExecReload", "ExecStop", "ExecStopPost",
+		"Environment", "EnvironmentFile",
+		"ListenStream", "ListenDatagram", "ListenFIFO",
+		"OnCalendar", "OnBootSec", "OnUnitActiveSec",
+		"RestrictNamespaces",
+	}
+
+	overrideCmd = &cobra.Command{
+		Use:   "override <unit>",
+		Short: "creates a drop-in overriding parts of a Unit",
+		Long:  `The override command creates a drop-in file which only changes some options of an existing Unit`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ts, err := targets()
+			if err != nil {
+				return fmt.Errorf("Can't find systemd targets: %s", err)
+			}
+
+			name := args[0]
+			if !strings.Contains(name, ".") {
+				name += ".service"
+			}
+			if len(overrideDir) == 0 {
+				overrideDir = defaultUnitDir()
+			}
+			filename := filepath.Join(overrideDir, name+".d", overrideName+".conf")
+
+			base, err := effectiveOptions(name, filename)
+			if err != nil {
+				return err
+			}
+
+			// start off with a previously written version of this drop-in
+			current := base
+			if _, err := os.Stat(filename); err == nil {
+				dropin, err := readUnit(filename)
+				if err != nil {
+					return err
+				}
+				current = mergeOptions(base, dropin)
+			}
+
+			if err := loadOptions(current, strings.Contains(name, "@")); err != nil {
+				return err
+			}
+			if err := validateTypes(); err != nil {
+				return err
+			}
+			before := serviceOptions()
+
+			preview := func() string {
+				opts, _ := dropinOptions(before)
+				return fmt.Sprintf("# %s\n%s", filename, serializeOptions(opts))
+			}
+			return runForm("Override "+name, "Save", ts, preview, func() error {
+				return executeOverride(filename, before)
+			})
+		},
+	}
+)
+
+// effectiveOptions returns the options of a Unit as systemd sees them: its
+// Unit file merged with all its drop-ins, except the one at skip.
+func effectiveOptions(name, skip string) ([]*unit.UnitOption, error) {
+	conn, err := connection()
+	if err != nil {
+		return nil, fmt.Errorf("Can't connect to systemd: %s", err)
+	}
+
+	p, err := conn.GetUnitProperty(name, "FragmentPath")
+	if err != nil {
+		return nil, fmt.Errorf("Can't find Unit %s: %s", name, err)
+	}
+	fragment, _ := p.Value.Value().(string)
+	if len(fragment) == 0 {
+		return nil, fmt.Errorf("Can't find a Unit file for %s", name)
+	}
+	opts, err := readUnit(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err = conn.GetUnitProperty(name, "DropInPaths")
+	if err != nil {
+		return nil, fmt.Errorf("Can't find drop-ins of %s: %s", name, err)
+	}
+	dropins, _ := p.Value.Value().([]string)
+	for _, d := range dropins {
+		if d == skip {
+			continue
+		}
+
+		dopts, err := readUnit(d)
+		if err != nil {
+			return nil, err
+		}
+		opts = mergeOptions(opts, dopts)
+	}
+
+	return opts, nil
+}
+
+// mergeOptions applies the options of a drop-in to the options of a Unit.
+func mergeOptions(opts, dropin []*unit.UnitOption) []*unit.UnitOption {
+	res := append([]*unit.UnitOption{}, opts...)
+	for _, d := range dropin {
+		if !listOptions.Contains(d.Name) || len(d.Value) == 0 {
+			// single value options get replaced, list options reset
+			var kept []*unit.UnitOption
+			for _, o := range res {
+				if o.Section != d.Section || o.Name != d.Name {
+					kept = append(kept, o)
+				}
+			}
+			res = kept
+		}
+		if len(d.Value) > 0 {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
+
+// diffOptions returns the options which need to be assigned in a drop-in to
+// turn before into after.
+func diffOptions(before, after []*unit.UnitOption) []*unit.UnitOption {
+	var keys []string
+	values := func(opts []*unit.UnitOption) map[string][]string {
+		res := map[string][]string{}
+		for _, o := range opts {
+			key := o.Section + "." + o.Name
+			if !Strings(keys).Contains(key) {
+				keys = append(keys, key)
+			}
+			res[key] = append(res[key], o.Value)
+		}
+		return res
+	}
+	a := values(after)
+	b := values(before)
+
+	var res []*unit.UnitOption
+	for _, key := range keys {
+		if strings.Join(a[key], "\n") == strings.Join(b[key], "\n") {
+			continue
+		}
+
+		kv := strings.SplitN(key, ".", 2)
+		section, name := kv[0], kv[1]
+		if !listOptions.Contains(name) {
+			// unset options fall back to their default
+			v := ""
+			if len(a[key]) > 0 {
+				v = a[key][len(a[key])-1]
+			}
+			res = append(res, &unit.UnitOption{section, name, v})
+			continue
+		}
+
+		if len(b[key]) > 0 {
+			res = append(res, &unit.UnitOption{section, name, ""})
+		}
+		for _, v := range a[key] {
+			res = append(res, &unit.UnitOption{section, name, v})
+		}
+	}
+
+	return res
+}
+
+// dropinOptions returns the options of the drop-in changing before to the
+// options described by createOpts, and the changes a drop-in can't make.
+func dropinOptions(before []*unit.UnitOption) ([]*unit.UnitOption, []*unit.UnitOption) {
+	var opts, ignored []*unit.UnitOption
+	for _, o := range diffOptions(before, serviceOptions()) {
+		if o.Section == "Install" {
+			ignored = append(ignored, o)
+			continue
+		}
+		opts = append(opts, o)
+	}
+
+	return opts, ignored
+}
+
+// validateDropin checks the values a drop-in adds to before. Everything else
+// comes from the Unit as it's installed, which systemd accepts already, even
+// if it e.g. refers to units that aren't installed.
+func validateDropin(before, dropin []*unit.UnitOption) error {
+	assigned := map[string]Strings{}
+	for _, o := range before {
+		assigned[o.Section+"."+o.Name] = append(assigned[o.Section+"."+o.Name], o.Value)
+	}
+	var added []*unit.UnitOption
+	for _, o := range dropin {
+		key := o.Section + "." + o.Name
+		value := o.Value
+		if dependencyField(o.Name) != nil {
+			// dependencies list units, only check the new ones
+			units := Strings(strings.Fields(strings.Join(assigned[key], " ")))
+			var fresh []string
+			for _, u := range strings.Fields(value) {
+				if !units.Contains(u) {
+					fresh = append(fresh, u)
+				}
+			}
+			value = strings.Join(fresh, " ")
+		}
+		if len(value) > 0 && !assigned[key].Contains(value) {
+			added = append(added, &unit.UnitOption{o.Section, o.Name, value})
+		}
+	}
+
+	edited, extra := createOpts, extraOptions
+	defer func() {
+		createOpts, extraOptions = edited, extra
+	}()
+	if err := loadOptions(added, edited.Template); err != nil {
+		return err
+	}
+	if err := validateTypes(); err != nil {
+		return err
+	}
+	return validateOptions()
+}
+
+func executeOverride(filename string, before []*unit.UnitOption) error {
+	opts, ignored := dropinOptions(before)
+	for _, o := range ignored {
+		fmt.Printf("Ignoring %s=%s: the Install section can't be changed by drop-ins\n", o.Name, o.Value)
+	}
+	if len(opts) == 0 {
+		fmt.Println("Nothing changed, no drop-in written")
+		return nil
+	}
+	if err := validateDropin(before, opts); err != nil {
+		return err
+	}
+	if secretEnv == "credential" {
+		if err := credentialEnvironment(strings.TrimSuffix(filename, ".conf")); err != nil {
+			return err
+		}
+		opts, _ = dropinOptions(before)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("Could not create drop-in directory: %s", err)
+	}
+	if err := writeUnit(filename, opts); err != nil {
+		return err
+	}
+
+	conn, err := connection()
+	if err != nil {
+		return fmt.Errorf("Can't connect to systemd: %s", err)
+	}
+	if err := conn.Reload(); err != nil {
+		return fmt.Errorf("Could not reload systemd: %s", err)
+	}
+
+	b, err := ioutil.ReadAll(unit.Serialize(mergeOptions(before, opts)))
+	if err != nil {
+		return fmt.Errorf("Encountered error while reading output: %v", err)
+	}
+	fmt.Printf("Effective Unit:\n%s\n", b)
+	return nil
+}
+
+func init() {
+	overrideCmd.PersistentFlags().StringVarP(&overrideName, "name", "n", "override", "Name of the drop-in file")
+	overrideCmd.PersistentFlags().StringVar(&overrideDir, "unitdir", "", "Directory to create the drop-in in (default /etc/systemd/system or ~/.config/systemd/user)")
+
+	RootCmd.AddCommand(overrideCmd)
+}
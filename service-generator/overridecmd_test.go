@@ -0,0 +1,161 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+// testOptions turns Section.Name=Value strings into unit options.
+func testOptions(opts ...string) []*unit.UnitOption {
+	var res []*unit.UnitOption
+	for _, o := range opts {
+		kv := strings.SplitN(o, "=", 2)
+		key := strings.SplitN(kv[0], ".", 2)
+		res = append(res, &unit.UnitOption{key[0], key[1], kv[1]})
+	}
+
+	return res
+}
+
+func optionStrings(opts []*unit.UnitOption) []string {
+	var res []string
+	for _, o := range opts {
+		res = append(res, o.Section+"."+o.Name+"="+o.Value)
+	}
+
+	return res
+}
+
+// optionValues returns the values assigned to each option, in order.
+func optionValues(opts []*unit.UnitOption) map[string][]string {
+	res := map[string][]string{}
+	for _, o := range opts {
+		res[o.Section+"."+o.Name] = append(res[o.Section+"."+o.Name], o.Value)
+	}
+
+	return res
+}
+
+func TestDiffOptions(t *testing.T) {
+	tests := []struct {
+		before []string
+		after  []string
+		dropin []string
+	}{
+		{
+			before: []string{"Service.User=www", "Unit.After=network.target"},
+			after:  []string{"Service.User=www", "Unit.After=network.target"},
+		},
+		{
+			before: []string{"Service.User=www", "Service.Nice=5"},
+			after:  []string{"Service.User=app", "Service.Nice=5"},
+			dropin: []string{"Service.User=app"},
+		},
+		{
+			// unset single value options get reset to their default
+			before: []string{"Service.User=www", "Service.Nice=5"},
+			after:  []string{"Service.User=www"},
+			dropin: []string{"Service.Nice="},
+		},
+		{
+			before: []string{"Service.User=www"},
+			after:  []string{"Service.User=www", "Service.RestartSec=5s"},
+			dropin: []string{"Service.RestartSec=5s"},
+		},
+		{
+			// list options get reset before they're assigned again
+			before: []string{"Unit.After=network.target", "Service.ExecStart=/usr/bin/app"},
+			after:  []string{"Unit.After=network.target", "Unit.After=postgresql.service", "Service.ExecStart=/usr/bin/app --debug"},
+			dropin: []string{"Unit.After=", "Unit.After=network.target", "Unit.After=postgresql.service", "Service.ExecStart=", "Service.ExecStart=/usr/bin/app --debug"},
+		},
+		{
+			before: []string{"Service.User=www"},
+			after:  []string{"Service.User=www", "Service.Environment=A=1", "Service.Environment=B=2"},
+			dropin: []string{"Service.Environment=A=1", "Service.Environment=B=2"},
+		},
+		{
+			before: []string{"Service.Environment=A=1", "Service.Environment=B=2"},
+			after:  []string{},
+			dropin: []string{"Service.Environment="},
+		},
+	}
+
+	for _, test := range tests {
+		before := testOptions(test.before...)
+		after := testOptions(test.after...)
+
+		dropin := diffOptions(before, after)
+		if !reflect.DeepEqual(optionStrings(dropin), test.dropin) {
+			t.Errorf("diffOptions(%q, %q) = %q, expected %q", test.before, test.after, optionStrings(dropin), test.dropin)
+		}
+
+		// applying the drop-in needs to result in the options wanted
+		merged := mergeOptions(before, dropin)
+		if !reflect.DeepEqual(optionValues(merged), optionValues(after)) {
+			t.Errorf("mergeOptions(%q, %q) = %q, expected %q", test.before, optionStrings(dropin), optionStrings(merged), test.after)
+		}
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	tests := []struct {
+		opts   []string
+		dropin []string
+		merged []string
+	}{
+		{
+			opts:   []string{"Service.User=www", "Service.Nice=5"},
+			dropin: []string{"Service.User=app"},
+			merged: []string{"Service.Nice=5", "Service.User=app"},
+		},
+		{
+			opts:   []string{"Unit.After=network.target"},
+			dropin: []string{"Unit.After=postgresql.service"},
+			merged: []string{"Unit.After=network.target", "Unit.After=postgresql.service"},
+		},
+		{
+			opts:   []string{"Service.ExecStartPre=/bin/a", "Service.ExecStartPre=/bin/b"},
+			dropin: []string{"Service.ExecStartPre=", "Service.ExecStartPre=/bin/c"},
+			merged: []string{"Service.ExecStartPre=/bin/c"},
+		},
+		{
+			opts:   []string{"Service.Nice=5"},
+			dropin: []string{"Service.Nice="},
+		},
+	}
+
+	for _, test := range tests {
+		merged := mergeOptions(testOptions(test.opts...), testOptions(test.dropin...))
+		if !reflect.DeepEqual(optionStrings(merged), test.merged) {
+			t.Errorf("mergeOptions(%q, %q) = %q, expected %q", test.opts, test.dropin, optionStrings(merged), test.merged)
+		}
+	}
+}
+
+func TestValidateDropin(t *testing.T) {
+	before := testOptions("Service.Type=simple", "Service.ExecStart=/usr/bin/app")
+	tests := []struct {
+		dropin []string
+		valid  bool
+	}{
+		{[]string{"Service.Type=notify"}, true},
+		{[]string{"Service.Type=bogus"}, false},
+		{[]string{"Service.Restart=sometimes"}, false},
+		{[]string{"Service.LogRateLimitIntervalSec=30s"}, true},
+		{[]string{"Service.LogRateLimitIntervalSec=5 parsecs"}, false},
+	}
+
+	for _, test := range tests {
+		createOpts, extraOptions = CreateOptions{}, nil
+		err := validateDropin(before, testOptions(test.dropin...))
+		if test.valid && err != nil {
+			t.Errorf("validateDropin(%q): %s", test.dropin, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validateDropin(%q): expected an error", test.dropin)
+		}
+	}
+}
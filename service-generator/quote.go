@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var unescapes = map[rune]rune{
+	'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+	'\\': '\\', '"': '"', '\'': '\'', ' ': ' ',
+}
+
+// splitQuoted splits s into words the way systemd does for command lines and
+// environment assignments: words are separated by whitespace, may be quoted
+// with single or double quotes and support C-style backslash escapes.
+func splitQuoted(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if u, ok := unescapes[r]; ok {
+				word.WriteRune(u)
+			} else {
+				word.WriteRune('\\')
+				word.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return words, fmt.Errorf("Trailing backslash in: %s", s)
+	}
+	if quote != 0 {
+		return words, fmt.Errorf("Unterminated quote in: %s", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// quoteWord returns s double-quoted and escaped if it contains characters
+// which would otherwise split it or be interpreted by systemd.
+func quoteWord(s string) string {
+	if len(s) > 0 && !strings.ContainsAny(s, " \t\n\"'\\") {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+		err  bool
+	}{
+		{s: "", want: nil},
+		{s: "a b  c", want: []string{"a", "b", "c"}},
+		{s: "\ta\n b ", want: []string{"a", "b"}},
+		{s: `"a b" 'c d'`, want: []string{"a b", "c d"}},
+		{s: `a"b c"d`, want: []string{"ab cd"}},
+		{s: `""`, want: []string{""}},
+		{s: `a\ b`, want: []string{"a b"}},
+		{s: `"x\"y"`, want: []string{`x"y`}},
+		{s: `'it"s'`, want: []string{`it"s`}},
+		{s: `a\nb\tc`, want: []string{"a\nb\tc"}},
+		{s: `\d+`, want: []string{`\d+`}},
+		{s: `"abc`, err: true},
+		{s: `abc\`, err: true},
+	}
+
+	for _, test := range tests {
+		words, err := splitQuoted(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("splitQuoted(%q): expected an error, got %q", test.s, words)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitQuoted(%q): %s", test.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(words, test.want) {
+			t.Errorf("splitQuoted(%q) = %q, expected %q", test.s, words, test.want)
+		}
+	}
+}
+
+func TestQuoteWord(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"abc", "abc"},
+		{"/usr/bin/app", "/usr/bin/app"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{`a"b`, `"a\"b"`},
+		{"it's", `"it's"`},
+		{`a\b`, `"a\\b"`},
+		{"a\nb\tc", `"a\nb\tc"`},
+	}
+
+	for _, test := range tests {
+		q := quoteWord(test.s)
+		if q != test.want {
+			t.Errorf("quoteWord(%q) = %s, expected %s", test.s, q, test.want)
+		}
+
+		// quoted words need to survive being split again
+		words, err := splitQuoted(q)
+		if err != nil || len(words) != 1 || words[0] != test.s {
+			t.Errorf("splitQuoted(quoteWord(%q)) = %q, %v", test.s, words, err)
+		}
+	}
+}
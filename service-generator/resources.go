@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	byteSize   = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?[KMGTPE]?$`)
+	percentage = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%$`)
+	sliceName  = regexp.MustCompile(`^[A-Za-z0-9:_.\\-]+\.slice$`)
+)
+
+func validateMemory(name, value string) error {
+	if len(value) == 0 || value == "infinity" || byteSize.MatchString(value) || percentage.MatchString(value) {
+		return nil
+	}
+
+	return fmt.Errorf("Invalid value for %s: %s (expected bytes with optional K, M, G, T suffix, a percentage or infinity)", name, value)
+}
+
+func validateWeight(name, value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 10000 {
+		return fmt.Errorf("Invalid value for %s: %s (expected 1 to 10000)", name, value)
+	}
+
+	return nil
+}
+
+func validateResources() error {
+	if err := validateMemory("MemoryMax", createOpts.MemoryMax); err != nil {
+		return err
+	}
+	if err := validateMemory("MemoryHigh", createOpts.MemoryHigh); err != nil {
+		return err
+	}
+
+	if len(createOpts.CPUQuota) > 0 && !percentage.MatchString(createOpts.CPUQuota) {
+		return fmt.Errorf("Invalid value for CPUQuota: %s (expected a percentage)", createOpts.CPUQuota)
+	}
+	if err := validateWeight("CPUWeight", createOpts.CPUWeight); err != nil {
+		return err
+	}
+	if err := validateWeight("IOWeight", createOpts.IOWeight); err != nil {
+		return err
+	}
+
+	if v := createOpts.TasksMax; len(v) > 0 && v != "infinity" && !percentage.MatchString(v) {
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+			return fmt.Errorf("Invalid value for TasksMax: %s (expected a number, a percentage or infinity)", v)
+		}
+	}
+	if v := createOpts.LimitNOFILE; len(v) > 0 {
+		// limits can be given as soft:hard
+		for _, l := range strings.SplitN(v, ":", 2) {
+			if _, err := strconv.ParseUint(l, 10, 64); err != nil && l != "infinity" {
+				return fmt.Errorf("Invalid value for LimitNOFILE: %s (expected a number, soft:hard or infinity)", v)
+			}
+		}
+	}
+
+	if len(createOpts.Slice) > 0 && !sliceName.MatchString(createOpts.Slice) {
+		return fmt.Errorf("Invalid slice name: %s", createOpts.Slice)
+	}
+
+	return nil
+}
+
+func init() {
+	createCmd.PersistentFlags().StringVar(&createOpts.MemoryMax, "memorymax", "", "Hard memory limit (bytes with K, M, G or T suffix, percentage or infinity)")
+	createCmd.PersistentFlags().StringVar(&createOpts.MemoryHigh, "memoryhigh", "", "Memory usage above which the service gets throttled")
+	createCmd.PersistentFlags().StringVar(&createOpts.CPUQuota, "cpuquota", "", "CPU time quota relative to one CPU (e.g. 150%)")
+	createCmd.PersistentFlags().StringVar(&createOpts.CPUWeight, "cpuweight", "", "Relative CPU weight (1 to 10000)")
+	createCmd.PersistentFlags().StringVar(&createOpts.IOWeight, "ioweight", "", "Relative IO weight (1 to 10000)")
+	createCmd.PersistentFlags().StringVar(&createOpts.TasksMax, "tasksmax", "", "Maximum number of tasks (number, percentage or infinity)")
+	createCmd.PersistentFlags().StringVar(&createOpts.LimitNOFILE, "limitnofile", "", "Maximum number of open files (number, soft:hard or infinity)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Slice, "slice", "", "Slice to run the service in")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type exposureCheck struct {
+	Directive string
+	Weight    int
+	Rationale string
+	// Exposure returns how exposed a service is, from 0 (safe) to 1
+	Exposure func(values map[string]string) float64
+}
+
+type Exposure struct {
+	Directive string  `json:"directive"`
+	Weight    int     `json:"weight"`
+	Exposure  float64 `json:"exposure"`
+	Rationale string  `json:"rationale"`
+}
+
+type Score struct {
+	File      string     `json:"file"`
+	Score     float64    `json:"score"`
+	Level     string     `json:"level"`
+	Exposures []Exposure `json:"exposures"`
+}
+
+var (
+	scoreJSON bool
+
+	exposureChecks = []exposureCheck{
+		{"User", 10, "Service runs as root and has full access to the system", func(v map[string]string) float64 {
+			if isTrue(v["DynamicUser"]) {
+				return 0
+			}
+			if u := v["User"]; len(u) > 0 && u != "root" && u != "0" {
+				return 0
+			}
+			return 1
+		}},
+		{"NoNewPrivileges", 5, "Service processes may acquire new privileges, e.g. through setuid binaries", enabled("NoNewPrivileges")},
+		{"ProtectSystem", 5, "Service may modify the operating system's files", func(v map[string]string) float64 {
+			switch v["ProtectSystem"] {
+			case "strict":
+				return 0
+			case "full":
+				return 0.3
+			}
+			if isTrue(v["ProtectSystem"]) {
+				return 0.5
+			}
+			return 1
+		}},
+		{"ProtectHome", 5, "Service may access the users' home directories", func(v map[string]string) float64 {
+			switch v["ProtectHome"] {
+			case "read-only":
+				return 0.5
+			case "tmpfs":
+				return 0
+			}
+			return enabled("ProtectHome")(v)
+		}},
+		{"CapabilityBoundingSet", 4, "Service processes may hold any capability", isSet("CapabilityBoundingSet")},
+		{"SystemCallFilter", 4, "Service may use any system call", isSet("SystemCallFilter")},
+		{"PrivateTmp", 4, "Service shares /tmp with all other processes", enabled("PrivateTmp")},
+		{"PrivateDevices", 4, "Service has access to hardware devices", enabled("PrivateDevices")},
+		{"PrivateNetwork", 3, "Service has access to the host's network", enabled("PrivateNetwork")},
+		{"RestrictAddressFamilies", 3, "Service may use any socket address family", isSet("RestrictAddressFamilies")},
+		{"RestrictNamespaces", 3, "Service may create kernel namespaces", isSet("RestrictNamespaces")},
+		{"ProtectKernelTunables", 3, "Service may alter kernel tunables in /proc/sys and /sys", enabled("ProtectKernelTunables")},
+		{"ProtectKernelModules", 3, "Service may load kernel modules", enabled("ProtectKernelModules")},
+		{"ProtectControlGroups", 3, "Service may modify the control group hierarchy", enabled("ProtectControlGroups")},
+		{"ProtectKernelLogs", 2, "Service may read from and write to the kernel log", enabled("ProtectKernelLogs")},
+		{"ProtectClock", 2, "Service may change the system clock", enabled("ProtectClock")},
+		{"PrivateUsers", 2, "Service has access to all users of the host", enabled("PrivateUsers")},
+		{"SystemCallArchitectures", 2, "Service may use system calls of foreign architectures", func(v map[string]string) float64 {
+			if v["SystemCallArchitectures"] == "native" {
+				return 0
+			}
+			return 1
+		}},
+		{"MemoryDenyWriteExecute", 2, "Service may create writable and executable memory mappings", enabled("MemoryDenyWriteExecute")},
+		{"RestrictRealtime", 2, "Service may acquire realtime scheduling and starve the system", enabled("RestrictRealtime")},
+		{"RestrictSUIDSGID", 2, "Service may create setuid and setgid files", enabled("RestrictSUIDSGID")},
+		{"ProtectHostname", 1, "Service may change the system's hostname", enabled("ProtectHostname")},
+		{"LockPersonality", 1, "Service may change the kernel's execution domain", enabled("LockPersonality")},
+		{"RemoveIPC", 1, "IPC objects of the service outlive it", enabled("RemoveIPC")},
+	}
+
+	// noNewPrivilegesImplied are the directives which imply NoNewPrivileges=
+	// for services not running as root
+	noNewPrivilegesImplied = Strings{"SystemCallFilter", "SystemCallArchitectures", "RestrictAddressFamilies", "RestrictNamespaces", "PrivateDevices", "ProtectKernelTunables", "ProtectKernelModules", "ProtectKernelLogs", "ProtectClock", "MemoryDenyWriteExecute", "RestrictRealtime", "RestrictSUIDSGID", "DynamicUser", "LockPersonality"}
+
+	scoreCmd = &cobra.Command{
+		Use:   "score <unit-file>...",
+		Short: "rates the security exposure of Unit files",
+		Long:  `The score command rates how exposed a service is, based on the hardening directives in its Unit file`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scores := []Score{}
+			for _, filename := range args {
+				s, err := scoreUnit(filename)
+				if err != nil {
+					return err
+				}
+				scores = append(scores, s)
+			}
+
+			if scoreJSON {
+				b, err := json.MarshalIndent(scores, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			for _, s := range scores {
+				fmt.Printf("%s:\n", s.File)
+				for _, e := range s.Exposures {
+					fmt.Printf("  %-24s %4.1f  %s\n", e.Directive+"=", float64(e.Weight)*e.Exposure, e.Rationale)
+				}
+				fmt.Printf("Overall exposure level: %.1f %s\n\n", s.Score, s.Level)
+			}
+			return nil
+		},
+	}
+)
+
+func isTrue(s string) bool {
+	return Strings{"yes", "true", "on", "1"}.Contains(strings.ToLower(s))
+}
+
+func isFalse(s string) bool {
+	return Strings{"no", "false", "off", "0"}.Contains(strings.ToLower(s))
+}
+
+func enabled(directive string) func(map[string]string) float64 {
+	return func(v map[string]string) float64 {
+		if isTrue(v[directive]) {
+			return 0
+		}
+		return 1
+	}
+}
+
+func isSet(directive string) func(map[string]string) float64 {
+	return func(v map[string]string) float64 {
+		if len(v[directive]) > 0 && !isFalse(v[directive]) {
+			return 0
+		}
+		return 1
+	}
+}
+
+func exposureLevel(score float64) string {
+	switch {
+	case score < 2:
+		return "SAFE"
+	case score < 4.5:
+		return "OK"
+	case score < 7:
+		return "MEDIUM"
+	case score < 9:
+		return "EXPOSED"
+	default:
+		return "UNSAFE"
+	}
+}
+
+// implySettings adds the settings systemd implies for the ones which are
+// set, like systemd-analyze security does.
+func implySettings(values map[string]string) {
+	unset := func(directive string) bool {
+		return len(values[directive]) == 0 || isFalse(values[directive])
+	}
+
+	if isTrue(values["DynamicUser"]) {
+		for _, directive := range []string{"PrivateTmp", "RemoveIPC", "RestrictSUIDSGID"} {
+			values[directive] = "yes"
+		}
+		if unset("ProtectSystem") {
+			values["ProtectSystem"] = "strict"
+		}
+		if unset("ProtectHome") {
+			values["ProtectHome"] = "read-only"
+		}
+	}
+
+	// services without CAP_SYS_ADMIN can only be restricted like this with
+	// NoNewPrivileges=
+	if u := values["User"]; isTrue(values["DynamicUser"]) || (len(u) > 0 && u != "root" && u != "0") {
+		for _, directive := range noNewPrivilegesImplied {
+			if !unset(directive) {
+				values["NoNewPrivileges"] = "yes"
+			}
+		}
+	}
+}
+
+// scoreUnit rates a Unit file from 0 (safe) to 10 (fully exposed) and lists
+// all hardening directives which would lower its exposure.
+func scoreUnit(filename string) (Score, error) {
+	opts, err := readUnit(filename)
+	if err != nil {
+		return Score{}, err
+	}
+
+	// later assignments override earlier ones
+	values := map[string]string{}
+	for _, opt := range opts {
+		if opt.Section == "Service" {
+			values[opt.Name] = opt.Value
+		}
+	}
+
+	s := scoreValues(values)
+	s.File = filename
+	return s, nil
+}
+
+// scoreValues rates the values of a service's directives.
+func scoreValues(values map[string]string) Score {
+	implySettings(values)
+
+	s := Score{Exposures: []Exposure{}}
+	var total, exposed float64
+	for _, c := range exposureChecks {
+		e := c.Exposure(values)
+		total += float64(c.Weight)
+		exposed += float64(c.Weight) * e
+		if e > 0 {
+			s.Exposures = append(s.Exposures, Exposure{c.Directive, c.Weight, e, c.Rationale})
+		}
+	}
+
+	s.Score = float64(int(100*exposed/total+0.5)) / 10
+	s.Level = exposureLevel(s.Score)
+	return s
+}
+
+func init() {
+	scoreCmd.PersistentFlags().BoolVar(&scoreJSON, "json", false, "Report the scores as JSON")
+
+	RootCmd.AddCommand(scoreCmd)
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestScoreValues(t *testing.T) {
+	tests := []struct {
+		values  map[string]string
+		score   float64
+		level   string
+		exposed Strings
+		safe    Strings
+	}{
+		{
+			values:  map[string]string{},
+			score:   10,
+			level:   "UNSAFE",
+			exposed: Strings{"User", "NoNewPrivileges", "PrivateTmp", "RemoveIPC"},
+		},
+		{
+			values:  map[string]string{"User": "root", "PrivateTmp": "no"},
+			score:   10,
+			level:   "UNSAFE",
+			exposed: Strings{"User", "PrivateTmp"},
+		},
+		{
+			values:  map[string]string{"User": "www", "ProtectSystem": "full", "ProtectHome": "yes", "PrivateTmp": "yes"},
+			score:   7.0,
+			level:   "EXPOSED",
+			exposed: Strings{"ProtectSystem", "NoNewPrivileges"},
+			safe:    Strings{"User", "ProtectHome", "PrivateTmp"},
+		},
+		{
+			// DynamicUser= implies a few sandboxing directives
+			values:  map[string]string{"DynamicUser": "yes"},
+			score:   6.1,
+			level:   "MEDIUM",
+			exposed: Strings{"ProtectHome"},
+			safe:    Strings{"User", "NoNewPrivileges", "PrivateTmp", "RemoveIPC", "RestrictSUIDSGID", "ProtectSystem"},
+		},
+		{
+			// but doesn't weaken explicit settings
+			values: map[string]string{"DynamicUser": "yes", "ProtectHome": "tmpfs", "ProtectSystem": "full"},
+			score:  6.0,
+			level:  "MEDIUM",
+			safe:   Strings{"ProtectHome"},
+		},
+		{
+			// NoNewPrivileges= is only implied for unprivileged services
+			values:  map[string]string{"SystemCallFilter": "@system-service"},
+			exposed: Strings{"NoNewPrivileges"},
+			safe:    Strings{"SystemCallFilter"},
+			score:   9.5,
+			level:   "UNSAFE",
+		},
+		{
+			values: map[string]string{"User": "www", "SystemCallFilter": "@system-service"},
+			safe:   Strings{"NoNewPrivileges", "SystemCallFilter"},
+			score:  7.5,
+			level:  "EXPOSED",
+		},
+	}
+
+	for _, test := range tests {
+		s := scoreValues(test.values)
+		if s.Score != test.score || s.Level != test.level {
+			t.Errorf("scoreValues(%v) = %.1f %s, expected %.1f %s", test.values, s.Score, s.Level, test.score, test.level)
+		}
+
+		var exposed Strings
+		for _, e := range s.Exposures {
+			exposed = append(exposed, e.Directive)
+		}
+		for _, d := range test.exposed {
+			if !exposed.Contains(d) {
+				t.Errorf("scoreValues(%v): expected %s to be exposed", test.values, d)
+			}
+		}
+		for _, d := range test.safe {
+			if exposed.Contains(d) {
+				t.Errorf("scoreValues(%v): expected %s not to be exposed", test.values, d)
+			}
+		}
+	}
+}
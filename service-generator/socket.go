@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type SocketOptions struct {
+	Enabled bool `json:"-"`
+
+	ListenStream   string `json:"listenstream"`
+	ListenDatagram string `json:"listendatagram"`
+	ListenFIFO     string `json:"listenfifo"`
+	Accept         bool   `json:"accept"`
+
+	SocketUser   string `json:"socketuser"`
+	SocketMode   string `json:"socketmode"`
+	BindIPv6Only string `json:"bindipv6only"`
+}
+
+var bindIPv6Onlys = Strings{"default", "both", "ipv6-only"}
+
+// validateSocket checks the socket options, if socket activation is wanted.
+func validateSocket() error {
+	s := createOpts.Socket
+	if !s.Enabled {
+		return nil
+	}
+
+	if len(s.ListenStream) == 0 && len(s.ListenDatagram) == 0 && len(s.ListenFIFO) == 0 {
+		return fmt.Errorf("Socket needs at least one of ListenStream, ListenDatagram or ListenFIFO")
+	}
+	if len(s.ListenFIFO) > 0 && !filepath.IsAbs(s.ListenFIFO) {
+		return fmt.Errorf("ListenFIFO needs an absolute path: %s", s.ListenFIFO)
+	}
+	if len(s.SocketMode) > 0 {
+		if _, err := strconv.ParseUint(s.SocketMode, 8, 32); err != nil {
+			return fmt.Errorf("Invalid octal SocketMode: %s", s.SocketMode)
+		}
+	}
+	if len(s.BindIPv6Only) > 0 && !bindIPv6Onlys.Contains(s.BindIPv6Only) {
+		return fmt.Errorf("No such BindIPv6Only mode: %s", s.BindIPv6Only)
+	}
+
+	return nil
+}
+
+func socketOptions() []*unit.UnitOption {
+	s := createOpts.Socket
+	u := []*unit.UnitOption{
+		&unit.UnitOption{"Unit", "Description", fmt.Sprintf("%s socket", createOpts.Description)},
+
+		&unit.UnitOption{"Socket", "ListenStream", s.ListenStream},
+		&unit.UnitOption{"Socket", "ListenDatagram", s.ListenDatagram},
+		&unit.UnitOption{"Socket", "ListenFIFO", s.ListenFIFO},
+		&unit.UnitOption{"Socket", "SocketUser", s.SocketUser},
+		&unit.UnitOption{"Socket", "SocketMode", s.SocketMode},
+		&unit.UnitOption{"Socket", "BindIPv6Only", s.BindIPv6Only},
+	}
+	if s.Accept {
+		u = append(u, &unit.UnitOption{"Socket", "Accept", strconv.FormatBool(s.Accept)})
+	}
+	u = append(u, &unit.UnitOption{"Install", "WantedBy", "sockets.target"})
+
+	return stripEmptyOptions(u)
+}
+
+func init() {
+	createCmd.PersistentFlags().BoolVar(&createOpts.Socket.Enabled, "socket", false, "Generate a socket which starts the service on demand")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.ListenStream, "listenstream", "", "Stream address (port, IP:port or path) to listen on (implies --socket)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.ListenDatagram, "listendatagram", "", "Datagram address (port, IP:port or path) to listen on (implies --socket)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.ListenFIFO, "listenfifo", "", "Path of a FIFO to listen on (implies --socket)")
+	createCmd.PersistentFlags().BoolVar(&createOpts.Socket.Accept, "accept", false, "Start a service instance for each connection (generates a template service)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.SocketUser, "socketuser", "", "User owning the socket file")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.SocketMode, "socketmode", "", "Access mode of the socket file (octal)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Socket.BindIPv6Only, "bindipv6only", "", "IPv6 binding behaviour (default, both or ipv6-only)")
+}
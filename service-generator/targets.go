@@ -1,6 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/coreos/go-systemd/dbus"
@@ -8,25 +13,153 @@ import (
 
 type Targets []dbus.UnitStatus
 
+var (
+	// conn is the connection to systemd shared by all dbus calls
+	conn *dbus.Conn
+	// userMode talks to the user's service manager instead of the system's
+	userMode bool
+
+	// unitSource is where units get discovered: from systemd via dbus, from
+	// the unit files on disk or, by default, whichever is available
+	unitSource  string
+	unitSources = Strings{"auto", "dbus", "files"}
+	// unitRoot is the directory the unit search paths are relative to
+	unitRoot string
+
+	unitSuffixes = Strings{".service", ".socket", ".target", ".timer", ".mount", ".automount", ".swap", ".path", ".slice", ".scope", ".device"}
+)
+
+func connection() (*dbus.Conn, error) {
+	if conn != nil {
+		return conn, nil
+	}
+
+	var err error
+	if userMode {
+		conn, err = dbus.NewUserConnection()
+	} else {
+		conn, err = dbus.New()
+	}
+	return conn, err
+}
+
 func targets() (Targets, error) {
 	res := []dbus.UnitStatus{}
-	conn, err := dbus.New()
+	us, err := units()
 	if err != nil {
 		return res, err
 	}
+	for _, u := range us {
+		if !strings.HasSuffix(u, ".target") {
+			continue
+		}
+
+		res = append(res, dbus.UnitStatus{Name: u})
+	}
+
+	return res, nil
+}
+
+// units returns the names of all known units, loaded or not.
+func units() (Strings, error) {
+	switch unitSource {
+	case "dbus":
+		return dbusUnits()
+	case "files":
+		return fileUnits()
+	case "", "auto":
+		if len(unitRoot) > 0 {
+			return fileUnits()
+		}
+		if us, err := dbusUnits(); err == nil {
+			return us, nil
+		}
+		// no service manager to ask, e.g. in containers and chroots
+		return fileUnits()
+	default:
+		return nil, fmt.Errorf("No such unit source: %s (expected one of %s)", unitSource, strings.Join(unitSources, ", "))
+	}
+}
+
+// dbusUnits asks systemd for its units.
+func dbusUnits() (Strings, error) {
+	conn, err := connection()
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
 	us, err := conn.ListUnits()
 	if err != nil {
-		return res, err
+		return nil, err
+	}
+	for _, u := range us {
+		set[u.Name] = true
+	}
+	ufs, err := conn.ListUnitFiles()
+	if err != nil {
+		return nil, err
 	}
-	for _, v := range us {
-		if !strings.HasSuffix(v.Name, ".target") {
+	for _, uf := range ufs {
+		set[filepath.Base(uf.Path)] = true
+	}
+
+	return sortedUnits(set), nil
+}
+
+// unitSearchPaths returns the directories systemd loads unit files from,
+// see systemd.unit(5).
+func unitSearchPaths() []string {
+	var paths []string
+	if userMode {
+		if len(unitRoot) == 0 {
+			paths = append(paths, defaultUnitDir())
+		}
+		paths = append(paths, "/etc/systemd/user", "/run/systemd/user", "/usr/local/lib/systemd/user", "/usr/lib/systemd/user")
+	} else {
+		paths = append(paths, "/etc/systemd/system", "/run/systemd/system", "/usr/local/lib/systemd/system", "/lib/systemd/system", "/usr/lib/systemd/system")
+	}
+
+	for i, p := range paths {
+		paths[i] = filepath.Join(unitRoot, p)
+	}
+	return paths
+}
+
+// fileUnits scans the unit search paths for unit files.
+func fileUnits() (Strings, error) {
+	set := map[string]bool{}
+	paths := unitSearchPaths()
+	for _, dir := range paths {
+		fis, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
 			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read unit directory: %s", err)
+		}
 
-		res = append(res, v)
+		for _, fi := range fis {
+			if !fi.IsDir() && unitSuffixes.Contains(filepath.Ext(fi.Name())) {
+				set[fi.Name()] = true
+			}
+		}
 	}
 
-	return res, nil
+	if len(set) == 0 {
+		return nil, fmt.Errorf("Could not find any unit files in %s", strings.Join(paths, ", "))
+	}
+	return sortedUnits(set), nil
+}
+
+func sortedUnits(set map[string]bool) Strings {
+	var res Strings
+	for name := range set {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
 }
 
 func (ts Targets) Contains(name string) bool {
@@ -47,3 +180,9 @@ func (ts Targets) Strings() []string {
 
 	return res
 }
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&userMode, "user-unit", false, "Work with units of the user's service manager instead of the system's")
+	RootCmd.PersistentFlags().StringVar(&unitSource, "unitsource", "auto", "Where to discover units (auto, dbus or files)")
+	RootCmd.PersistentFlags().StringVar(&unitRoot, "root", "", "Discover units in the unit files below this directory (implies --unitsource files)")
+}
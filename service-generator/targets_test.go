@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileUnits(t *testing.T) {
+	defer func(root string, user bool) {
+		unitRoot, userMode = root, user
+	}(unitRoot, userMode)
+
+	tests := []struct {
+		root string
+		user bool
+		want Strings
+	}{
+		{"testdata/root", false, Strings{"app.service", "getty@.service", "multi-user.target", "network.target", "sshd.service", "sshd.socket"}},
+		{"testdata/root", true, Strings{"pipewire.service"}},
+	}
+
+	for _, test := range tests {
+		unitRoot, userMode = test.root, test.user
+
+		units, err := fileUnits()
+		if err != nil {
+			t.Errorf("fileUnits() below %s: %s", test.root, err)
+			continue
+		}
+		if !reflect.DeepEqual(units, test.want) {
+			t.Errorf("fileUnits() below %s = %v, expected %v", test.root, units, test.want)
+		}
+	}
+
+	unitRoot, userMode = "testdata/nonexistent", false
+	if units, err := fileUnits(); err == nil {
+		t.Errorf("fileUnits() below an empty root: expected an error, got %v", units)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// specifiers lists all characters systemd accepts after a '%' in unit files
+const specifiers = "aAbBCdEfgGhHiIjJlLmMnNopPsStTuUvVwWyY%"
+
+// isTemplate returns whether the generated service is a template unit,
+// which gets instantiated as name@instance.service.
+func isTemplate() bool {
+	return createOpts.Template || (createOpts.Socket.Enabled && createOpts.Socket.Accept)
+}
+
+// validateSpecifiers checks that s only contains known specifiers and only
+// refers to the instance name in template units.
+func validateSpecifiers(s string) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		if i+1 == len(s) {
+			return fmt.Errorf("Incomplete specifier at the end of: %s", s)
+		}
+
+		c := s[i+1]
+		if !strings.ContainsRune(specifiers, rune(c)) {
+			return fmt.Errorf("Unknown specifier %%%c in: %s", c, s)
+		}
+		if (c == 'i' || c == 'I') && !isTemplate() {
+			return fmt.Errorf("Specifier %%%c can only be used in template units (see --template): %s", c, s)
+		}
+		i++
+	}
+
+	return nil
+}
+
+func validateTemplate() error {
+	if createOpts.Instances < 0 {
+		return fmt.Errorf("Number of instances can't be negative")
+	}
+	if createOpts.Instances > 0 && !isTemplate() {
+		return fmt.Errorf("Only template units can be instantiated (see --template)")
+	}
+
+	return nil
+}
+
+// instances returns the unit names of the instances to enable.
+func instances(name string) []string {
+	var res []string
+	for i := 1; i <= createOpts.Instances; i++ {
+		res = append(res, fmt.Sprintf("%s@%d.service", name, i))
+	}
+
+	return res
+}
+
+func init() {
+	createCmd.PersistentFlags().BoolVar(&createOpts.Template, "template", false, "Generate a template unit (name@.service) which can be instantiated")
+	createCmd.PersistentFlags().IntVar(&createOpts.Instances, "instances", 0, "Number of template instances (name@1 to name@N) to enable (implies --enable)")
+}
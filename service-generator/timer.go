@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+type TimerOptions struct {
+	Enabled bool `json:"-"`
+
+	OnCalendar      string `json:"oncalendar"`
+	OnBootSec       string `json:"onbootsec"`
+	OnUnitActiveSec string `json:"onunitactivesec"`
+	Persistent      bool   `json:"persistent"`
+
+	RandomizedDelaySec string `json:"randomizeddelaysec"`
+	AccuracySec        string `json:"accuracysec"`
+}
+
+// validateTimer checks the timer options and, if a timer is wanted, that the
+// service can run as the oneshot job the timer starts.
+func validateTimer() error {
+	t := createOpts.Timer
+	if !t.Enabled {
+		return nil
+	}
+
+	if len(t.OnCalendar) == 0 && len(t.OnBootSec) == 0 && len(t.OnUnitActiveSec) == 0 {
+		return fmt.Errorf("Timer needs at least one of OnCalendar, OnBootSec or OnUnitActiveSec")
+	}
+	// systemd refuses to load oneshot services which restart on success
+	if r := strings.ToLower(createOpts.Restart); r == "always" || r == "on-success" {
+		return fmt.Errorf("Restart=%s isn't allowed for services started by a timer, use on-failure or no", createOpts.Restart)
+	}
+	if len(t.OnCalendar) > 0 {
+		if err := validateCalendar(t.OnCalendar); err != nil {
+			return err
+		}
+	}
+	if err := validateTimeSpan("OnBootSec", t.OnBootSec); err != nil {
+		return err
+	}
+	if err := validateTimeSpan("OnUnitActiveSec", t.OnUnitActiveSec); err != nil {
+		return err
+	}
+	if err := validateTimeSpan("RandomizedDelaySec", t.RandomizedDelaySec); err != nil {
+		return err
+	}
+	if err := validateTimeSpan("AccuracySec", t.AccuracySec); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func timerOptions() []*unit.UnitOption {
+	t := createOpts.Timer
+	u := []*unit.UnitOption{
+		&unit.UnitOption{"Unit", "Description", fmt.Sprintf("%s timer", createOpts.Description)},
+
+		&unit.UnitOption{"Timer", "OnCalendar", t.OnCalendar},
+		&unit.UnitOption{"Timer", "OnBootSec", t.OnBootSec},
+		&unit.UnitOption{"Timer", "OnUnitActiveSec", t.OnUnitActiveSec},
+		&unit.UnitOption{"Timer", "RandomizedDelaySec", t.RandomizedDelaySec},
+		&unit.UnitOption{"Timer", "AccuracySec", t.AccuracySec},
+	}
+	if t.Persistent {
+		u = append(u, &unit.UnitOption{"Timer", "Persistent", strconv.FormatBool(t.Persistent)})
+	}
+	u = append(u, &unit.UnitOption{"Install", "WantedBy", "timers.target"})
+
+	return stripEmptyOptions(u)
+}
+
+func init() {
+	createCmd.PersistentFlags().BoolVar(&createOpts.Timer.Enabled, "timer", false, "Generate a timer which periodically starts the service")
+	createCmd.PersistentFlags().StringVar(&createOpts.Timer.OnCalendar, "oncalendar", "", "Calendar event expression to start the service on (implies --timer)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Timer.OnBootSec, "onbootsec", "", "Time after boot to start the service (implies --timer)")
+	createCmd.PersistentFlags().StringVar(&createOpts.Timer.OnUnitActiveSec, "onunitactivesec", "", "Time after the last activation to start the service again (implies --timer)")
+	createCmd.PersistentFlags().BoolVar(&createOpts.Timer.Persistent, "persistent", false, "Catch up on runs missed while the system was down")
+	createCmd.PersistentFlags().StringVar(&createOpts.Timer.RandomizedDelaySec, "randomizeddelaysec", "", "Maximum random delay added to each timer run")
+	createCmd.PersistentFlags().StringVar(&createOpts.Timer.AccuracySec, "accuracysec", "", "Accuracy the timer shall elapse with")
+}
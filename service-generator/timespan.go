@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// timeUnits maps all time span units systemd understands to their duration
+var timeUnits = map[string]time.Duration{
+	"usec": time.Microsecond, "us": time.Microsecond, "µs": time.Microsecond,
+	"msec": time.Millisecond, "ms": time.Millisecond,
+	"seconds": time.Second, "second": time.Second, "sec": time.Second, "s": time.Second, "": time.Second,
+	"minutes": time.Minute, "minute": time.Minute, "min": time.Minute, "m": time.Minute,
+	"hours": time.Hour, "hour": time.Hour, "hr": time.Hour, "h": time.Hour,
+	"days": 24 * time.Hour, "day": 24 * time.Hour, "d": 24 * time.Hour,
+	"weeks": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	"months": 2629800 * time.Second, "month": 2629800 * time.Second, "M": 2629800 * time.Second,
+	"years": 31557600 * time.Second, "year": 31557600 * time.Second, "y": 31557600 * time.Second,
+}
+
+// parseTimeSpan parses a systemd time span like "5min 20s" or "1h30m". A
+// plain number is interpreted as seconds.
+func parseTimeSpan(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return 0, fmt.Errorf("Empty time span")
+	}
+	if s == "infinity" {
+		return time.Duration(math.MaxInt64), nil
+	}
+
+	var d time.Duration
+	for len(s) > 0 {
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return !unicode.IsDigit(r) && r != '.'
+		})
+		if i < 0 {
+			i = len(s)
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("Invalid time span: %s", s)
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid time span: %s", s)
+		}
+
+		s = strings.TrimLeft(s[i:], " ")
+		j := strings.IndexFunc(s, func(r rune) bool {
+			return unicode.IsDigit(r) || r == ' '
+		})
+		if j < 0 {
+			j = len(s)
+		}
+		u, ok := timeUnits[s[:j]]
+		if !ok {
+			return 0, fmt.Errorf("Unknown time unit: %s", s[:j])
+		}
+
+		d += time.Duration(n * float64(u))
+		s = strings.TrimLeft(s[j:], " ")
+	}
+
+	return d, nil
+}
+
+// validateTimeSpan returns an error describing the option if value isn't a
+// valid time span. Empty values are considered valid.
+func validateTimeSpan(name, value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if _, err := parseTimeSpan(value); err != nil {
+		return fmt.Errorf("Invalid value for %s: %s", name, err)
+	}
+
+	return nil
+}
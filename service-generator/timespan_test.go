@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseTimeSpan(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		err  bool
+	}{
+		{s: "90", want: 90 * time.Second},
+		{s: "5min 20s", want: 5*time.Minute + 20*time.Second},
+		{s: "1h30m", want: 90 * time.Minute},
+		{s: "1.5s", want: 1500 * time.Millisecond},
+		{s: "500ms", want: 500 * time.Millisecond},
+		{s: "2 weeks", want: 14 * 24 * time.Hour},
+		{s: "1d 2h", want: 26 * time.Hour},
+		{s: "1y", want: 31557600 * time.Second},
+		{s: "10us", want: 10 * time.Microsecond},
+		{s: " 3 hours ", want: 3 * time.Hour},
+		{s: "infinity", want: time.Duration(math.MaxInt64)},
+		{s: "", err: true},
+		{s: "abc", err: true},
+		{s: "-5s", err: true},
+		{s: "5 parsecs", err: true},
+		{s: "1..2s", err: true},
+	}
+
+	for _, test := range tests {
+		d, err := parseTimeSpan(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseTimeSpan(%q): expected an error, got %s", test.s, d)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTimeSpan(%q): %s", test.s, err)
+			continue
+		}
+		if d != test.want {
+			t.Errorf("parseTimeSpan(%q) = %s, expected %s", test.s, d, test.want)
+		}
+	}
+}
+
+func TestValidateTimeSpan(t *testing.T) {
+	if err := validateTimeSpan("RestartSec", ""); err != nil {
+		t.Errorf("Empty time span should be valid: %s", err)
+	}
+	if err := validateTimeSpan("RestartSec", "5s"); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if err := validateTimeSpan("RestartSec", "5x"); err == nil {
+		t.Errorf("Expected an error for an unknown unit")
+	}
+}